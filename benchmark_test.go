@@ -0,0 +1,110 @@
+package getopt_test
+
+import (
+	"testing"
+
+	"github.com/rkennedy/go-getopt"
+)
+
+// benchArgs is a typical command line for the parsing benchmarks.
+var benchArgs = []string{
+	"program", "-v", "-o", "out.txt", "--level=3", "file1", "-xz", "--name", "build", "file2", "-l2", "--", "-f",
+}
+
+var benchLongOptions = []getopt.Option{
+	{Name: "level", HasArg: getopt.RequiredArgument, Val: 'l'},
+	{Name: "name", HasArg: getopt.RequiredArgument, Val: 'n'},
+	{Name: "verbose", HasArg: getopt.NoArgument, Val: 'v'},
+}
+
+const benchOpts = "vo:xzl:n:"
+
+// largeBenchArgs is a long command line that mixes clustered short options, attached arguments, long options, and
+// operands.
+var largeBenchArgs = func() []string {
+	args := []string{"program"}
+	for range 1000 {
+		args = append(args, "-vxz", "-ofile.out", "--level=3", "--name", "build", "operand", "-l2")
+	}
+	return args
+}()
+
+// benchParser returns a parser for benchArgs and a function that restores it to the start of the scan, so that each
+// iteration of a benchmark parses the same arguments.
+func benchParser() (*getopt.Getopt, func()) {
+	return benchParserFor(benchArgs)
+}
+
+// benchParserFor is like benchParser, but parses the given arguments.
+func benchParserFor(argv []string) (*getopt.Getopt, func()) {
+	args := make([]string, len(argv))
+	g := getopt.NewLong(args, benchOpts, benchLongOptions)
+	return g, func() {
+		copy(args, argv)
+		g.SetArgs(args)
+	}
+}
+
+func BenchmarkGetopt(b *testing.B) {
+	g, reset := benchParser()
+	b.ReportAllocs()
+	for range b.N {
+		reset()
+		for opt, err := g.Getopt(); opt != nil || err != nil; opt, err = g.Getopt() {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkNextInto(b *testing.B) {
+	g, reset := benchParser()
+	var opt getopt.Opt
+	b.ReportAllocs()
+	for range b.N {
+		reset()
+		for more, err := g.NextInto(&opt); more; more, err = g.NextInto(&opt) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkNextIntoLargeArgs(b *testing.B) {
+	g, reset := benchParserFor(largeBenchArgs)
+	var opt getopt.Opt
+	b.ReportAllocs()
+	for range b.N {
+		reset()
+		for more, err := g.NextInto(&opt); more; more, err = g.NextInto(&opt) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// benchSpec is a short option specification large enough that parsing it is a noticeable part of creating a parser.
+const benchSpec = "abcdefg:h:i::jklmnop:q:rstuvW;xyz#"
+
+func BenchmarkNew(b *testing.B) {
+	args := []string{"program"}
+	b.ReportAllocs()
+	for range b.N {
+		_ = getopt.New(args, benchSpec)
+	}
+}
+
+func BenchmarkNewWithSpec(b *testing.B) {
+	args := []string{"program"}
+	spec, err := getopt.CompileSpec(benchSpec)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		_ = getopt.NewWithSpec(args, spec)
+	}
+}
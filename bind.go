@@ -0,0 +1,164 @@
+package getopt
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BindError is returned by [Bind] when the struct it's given can't be used to define options. Field is the name of the
+// offending struct field, if any.
+type BindError struct {
+	Field  string
+	Reason string
+}
+
+func (e BindError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("cannot bind options: %s", e.Reason)
+	}
+	return fmt.Sprintf("cannot bind options to field %s: %s", e.Field, e.Reason)
+}
+
+// binder tracks the struct fields that receive the values of options defined by [Bind].
+type binder struct {
+	opts        strings.Builder
+	longOptions []Option
+	shortFields map[rune]reflect.Value
+	longFields  []reflect.Value
+}
+
+var stringSliceType = reflect.TypeFor[[]string]()
+
+// disposition returns the kind of argument that an option bound to a field of type t takes.
+func disposition(t reflect.Type) (ArgumentDisposition, bool) {
+	switch {
+	case t.Kind() == reflect.Bool:
+		return NoArgument, true
+	case t.Kind() == reflect.String, t.Kind() == reflect.Int, t == stringSliceType:
+		return RequiredArgument, true
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.String:
+		return RequiredArgument, true
+	default:
+		return NoArgument, false
+	}
+}
+
+// add defines the option described by a field's getopt tag.
+func (b *binder) add(field reflect.StructField, value reflect.Value, tag string) error {
+	if !field.IsExported() {
+		return BindError{Field: field.Name, Reason: "field is not exported"}
+	}
+	hasArg, ok := disposition(field.Type)
+	if !ok {
+		return BindError{Field: field.Name, Reason: fmt.Sprintf("unsupported type %s", field.Type)}
+	}
+	short, long, _ := strings.Cut(tag, ",")
+	if short == "" && long == "" {
+		return BindError{Field: field.Name, Reason: "no option names in tag"}
+	}
+
+	var c rune
+	if short != "" {
+		runes := []rune(short)
+		if len(runes) != 1 {
+			return BindError{Field: field.Name, Reason: fmt.Sprintf("short option %q is not a single character", short)}
+		}
+		c = runes[0]
+		if _, dup := b.shortFields[c]; dup {
+			return BindError{Field: field.Name, Reason: DuplicateOptionError{Name: short, Prefix: dash}.Error()}
+		}
+		b.shortFields[c] = value
+		_, _ = b.opts.WriteString(short)
+		if hasArg == RequiredArgument {
+			_, _ = b.opts.WriteString(":")
+		}
+	}
+	if long != "" {
+		b.longOptions = append(b.longOptions, Option{
+			Name:        long,
+			HasArg:      hasArg,
+			Val:         c,
+			Description: field.Tag.Get("usage"),
+		})
+		b.longFields = append(b.longFields, value)
+	}
+	return nil
+}
+
+// assign stores the result of an option in the field bound to it.
+func assign(field reflect.Value, opt *Opt) error {
+	switch {
+	case field.Kind() == reflect.Bool:
+		field.SetBool(true)
+	case field.Kind() == reflect.String:
+		field.SetString(*opt.Arg)
+	case field.Kind() == reflect.Int:
+		n, err := opt.Int()
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Pointer:
+		// Allocate the field's own element type, which may be a named string type.
+		p := reflect.New(field.Type().Elem())
+		p.Elem().SetString(*opt.Arg)
+		field.Set(p)
+	default:
+		field.Set(reflect.Append(field, reflect.ValueOf(*opt.Arg)))
+	}
+	return nil
+}
+
+// Bind defines options from the fields of the struct that v points to, parses args, and stores the results in the
+// struct. It returns the remaining non-option arguments.
+//
+// Each field that defines an option has a tag like `getopt:"b,bravo"` giving the option's short and long names.
+// Either name may be omitted, as in `getopt:"b"` or `getopt:",bravo"`. A `usage:"..."` tag supplies the option's
+// [Option.Description]. Fields without a getopt tag are ignored.
+//
+// The field's type determines what the option does:
+//   - bool: the option takes no argument and sets the field to true.
+//   - string or *string: the option requires an argument, which is stored in the field.
+//   - int: the option requires an argument, which is parsed as an integer.
+//   - []string: the option requires an argument and may be repeated. Each argument is appended to the field.
+//
+// Parsing uses [Permute] ordering and stops at the first error. A [BindError] is returned if v is not a pointer to a
+// struct or a tagged field can't be bound.
+func Bind(args []string, v any) (remaining []string, err error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, BindError{Reason: fmt.Sprintf("%T is not a pointer to a struct", v)}
+	}
+	rv = rv.Elem()
+
+	b := binder{shortFields: map[rune]reflect.Value{}}
+	for i := range rv.NumField() {
+		field := rv.Type().Field(i)
+		tag, ok := field.Tag.Lookup("getopt")
+		if !ok {
+			continue
+		}
+		if err := b.add(field, rv.Field(i), tag); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateOptions(b.longOptions); err != nil {
+		return nil, err
+	}
+
+	g := NewLong(args, b.opts.String(), b.longOptions)
+	for opt, parseErr := range g.All() {
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		field := b.shortFields[opt.C]
+		if opt.Long != nil {
+			field = b.longFields[opt.LongInd]
+		}
+		if err := assign(field, opt); err != nil {
+			return nil, err
+		}
+	}
+	return g.Remaining(), nil
+}
@@ -0,0 +1,111 @@
+package getopt_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Bind", func() {
+	type options struct {
+		Verbose  bool     `getopt:"v,verbose" usage:"print more"`
+		Output   string   `getopt:"o,output"`
+		Count    int      `getopt:"n"`
+		Label    *string  `getopt:",label"`
+		Includes []string `getopt:"I,include"`
+		Ignored  string
+	}
+
+	It("fills in the struct", func() {
+		var opts options
+		remaining, err := getopt.Bind([]string{
+			"program", "-v", "x", "--output=out", "-n", "3", "--label", "l", "-Ia", "--include", "b", "y",
+		}, &opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(HaveExactElements("x", "y"))
+		Expect(opts.Verbose).To(BeTrue())
+		Expect(opts.Output).To(Equal("out"))
+		Expect(opts.Count).To(Equal(3))
+		Expect(opts.Label).To(HaveValue(Equal("l")))
+		Expect(opts.Includes).To(HaveExactElements("a", "b"))
+		Expect(opts.Ignored).To(BeEmpty())
+	})
+
+	It("fills in pointers to named string types", func() {
+		type name string
+		var opts struct {
+			Name *name `getopt:"n,name"`
+		}
+		_, err := getopt.Bind([]string{"program", "--name", "x"}, &opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts.Name).To(HaveValue(Equal(name("x"))))
+	})
+
+	It("leaves absent options alone", func() {
+		opts := options{Output: "default"}
+		remaining, err := getopt.Bind([]string{"program"}, &opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeEmpty())
+		Expect(opts.Output).To(Equal("default"))
+		Expect(opts.Label).To(BeNil())
+	})
+
+	It("reports parse errors", func() {
+		var opts options
+		_, err := getopt.Bind([]string{"program", "-x"}, &opts)
+		Expect(err).To(MatchError("unrecognized option '-x'"))
+	})
+
+	It("reports conversion errors", func() {
+		var opts options
+		_, err := getopt.Bind([]string{"program", "-n", "many"}, &opts)
+		Expect(err).To(MatchError(getopt.ErrInvalidArgument))
+	})
+
+	It("requires a pointer to a struct", func() {
+		_, err := getopt.Bind([]string{"program"}, options{})
+		Expect(err).To(MatchError("cannot bind options: getopt_test.options is not a pointer to a struct"))
+	})
+
+	It("rejects unsupported types", func() {
+		var opts struct {
+			Rate float64 `getopt:"r"`
+		}
+		_, err := getopt.Bind([]string{"program"}, &opts)
+		Expect(err).To(MatchError(getopt.BindError{Field: "Rate", Reason: "unsupported type float64"}))
+	})
+
+	It("rejects malformed tags", func() {
+		var opts struct {
+			Name string `getopt:"na,name"`
+		}
+		_, err := getopt.Bind([]string{"program"}, &opts)
+		Expect(err).To(MatchError(`cannot bind options to field Name: short option "na" is not a single character`))
+	})
+
+	It("rejects duplicate options", func() {
+		var opts struct {
+			A bool `getopt:"a"`
+			B bool `getopt:"a,bee"`
+		}
+		_, err := getopt.Bind([]string{"program"}, &opts)
+		Expect(err).To(MatchError("cannot bind options to field B: option '-a' is defined more than once"))
+	})
+})
+
+func ExampleBind() {
+	var opts struct {
+		Verbose bool   `getopt:"v,verbose" usage:"print more output"`
+		Output  string `getopt:"o,output" usage:"write to a file"`
+	}
+	remaining, err := getopt.Bind([]string{"prg", "--verb", "input", "-o", "out.txt"}, &opts)
+	if err != nil {
+		_, _ = fmt.Println(err.Error())
+		return
+	}
+	_, _ = fmt.Printf("verbose=%v output=%s remaining=%v\n", opts.Verbose, opts.Output, remaining)
+	// Output: verbose=true output=out.txt remaining=[input]
+}
@@ -0,0 +1,96 @@
+package getopt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Builder assembles a short option specification and a matching list of long options one option at a time, so the two
+// can't drift apart. The zero value is ready to use.
+//
+// Each registration method takes a short option letter and a long option name; pass 0 or "" to omit either form. The
+// long option's Val is set to the short letter, so both forms are reported the same way by [Getopt.GetoptLong].
+// Registering a letter or name that is already registered is an error, as is a letter that has a special meaning in a
+// short option specification: '-', '+', ':', ';', or '#'. The registration is ignored, and the first such error is
+// reported by [Builder.Err].
+type Builder struct {
+	short       strings.Builder
+	longOptions []Option
+	shorts      map[rune]bool
+	longs       map[string]bool
+	err         error
+}
+
+// Flag registers an option that takes no argument.
+func (b *Builder) Flag(short rune, long string, desc string) *Builder {
+	return b.add(short, long, desc, NoArgument)
+}
+
+// String registers an option that requires an argument.
+func (b *Builder) String(short rune, long string, desc string) *Builder {
+	return b.add(short, long, desc, RequiredArgument)
+}
+
+// Optional registers an option that takes an optional argument.
+func (b *Builder) Optional(short rune, long string, desc string) *Builder {
+	return b.add(short, long, desc, OptionalArgument)
+}
+
+// specialSpecRunes holds the characters that can't be option letters because they mean something else in a short option
+// specification.
+const specialSpecRunes = "-+:;#"
+
+func (b *Builder) add(short rune, long string, desc string, hasArg ArgumentDisposition) *Builder {
+	if b.shorts == nil {
+		b.shorts = map[rune]bool{}
+		b.longs = map[string]bool{}
+	}
+	if strings.ContainsRune(specialSpecRunes, short) {
+		b.fail(SpecSyntaxError{
+			Pos:     utf8.RuneCountInString(b.short.String()),
+			Message: fmt.Sprintf("%q cannot be an option letter", short),
+		})
+		return b
+	}
+	if short != 0 && b.shorts[short] {
+		b.fail(DuplicateOptionError{Name: string(short), Prefix: dash})
+		return b
+	}
+	if long != "" && b.longs[long] {
+		b.fail(DuplicateOptionError{Name: long, Prefix: argumentTerminator})
+		return b
+	}
+	if short != 0 {
+		b.shorts[short] = true
+		_, _ = b.short.WriteRune(short)
+		_, _ = b.short.WriteString(strings.Repeat(":", int(hasArg)))
+	}
+	if long != "" {
+		b.longs[long] = true
+		b.longOptions = append(b.longOptions, Option{
+			Name:        long,
+			HasArg:      hasArg,
+			Val:         short,
+			Description: desc,
+		})
+	}
+	return b
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Err returns the first error encountered while registering options, or nil if there was none.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Build returns the short option specification and long options registered so far, ready to pass to [NewLong].
+func (b *Builder) Build() (opts string, longOptions []Option) {
+	return b.short.String(), slices.Clone(b.longOptions)
+}
@@ -0,0 +1,67 @@
+package getopt_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Builder", func() {
+	It("builds matching specifications", func() {
+		var b Builder
+		opts, longOptions := b.
+			Flag('v', "verbose", "print more").
+			String('o', "output", "write to file").
+			Optional('c', "color", "colorize").
+			Flag('q', "", "").
+			String(0, "level", "set level").
+			Build()
+		Expect(b.Err()).To(Succeed())
+		Expect(opts).To(Equal("vo:c::q"))
+		Expect(longOptions).To(Equal([]Option{
+			{Name: "verbose", Val: 'v', Description: "print more"},
+			{Name: "output", HasArg: RequiredArgument, Val: 'o', Description: "write to file"},
+			{Name: "color", HasArg: OptionalArgument, Val: 'c', Description: "colorize"},
+			{Name: "level", HasArg: RequiredArgument, Description: "set level"},
+		}))
+	})
+
+	It("rejects duplicate letters", func() {
+		var b Builder
+		opts, longOptions := b.Flag('v', "verbose", "").Flag('v', "version", "").Build()
+		Expect(b.Err()).To(MatchError(DuplicateOptionError{Name: "v", Prefix: "-"}))
+		Expect(opts).To(Equal("v"))
+		Expect(longOptions).To(HaveLen(1))
+	})
+
+	DescribeTable("rejects letters with special meanings",
+		func(c rune) {
+			var b Builder
+			opts, longOptions := b.Flag(c, "special", "").Flag('a', "all", "").Build()
+			Expect(b.Err()).To(MatchError(SpecSyntaxError{Pos: 0, Message: fmt.Sprintf("%q cannot be an option letter", c)}))
+			Expect(opts).To(Equal("a"))
+			Expect(longOptions).To(HaveExactElements(HaveField("Name", "all")))
+		},
+		Entry("in-order prefix", '-'),
+		Entry("require-order prefix", '+'),
+		Entry("argument marker", ':'),
+		Entry("W extension marker", ';'),
+		Entry("numeric marker", '#'),
+	)
+
+	It("rejects duplicate names", func() {
+		var b Builder
+		b.Flag('a', "all", "").String('b', "bravo", "").Flag('A', "all", "")
+		Expect(b.Err()).To(MatchError("option '--all' is defined more than once"))
+	})
+
+	It("parses what it builds", func() {
+		var b Builder
+		opts, longOptions := b.Flag('v', "verbose", "").String('o', "output", "").Build()
+		gopt := NewLong([]string{"prog", "--output=x", "-v"}, opts, longOptions)
+		Expect(gopt.GetoptLong()).To(HaveField("C", 'o'))
+		Expect(gopt.GetoptLong()).To(HaveField("C", 'v'))
+	})
+})
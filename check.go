@@ -0,0 +1,107 @@
+package getopt
+
+// seen reports whether an option with character c has been returned during the current parse.
+func (g *Getopt) seen(c rune) bool {
+	return g.counts[c] > 0
+}
+
+// seenLongOption reports whether the long option at index i has been given during the current parse, either by name
+// or, when its Flag is nil, by the short option whose character is its Val.
+func (g *Getopt) seenLongOption(i int) bool {
+	o := &g.longOptions[i]
+	return g.seenLong[i] || (o.Flag == nil && o.Val != 0 && g.seen(o.Val))
+}
+
+// Require marks the short options with the given characters as required. Use [Getopt.CheckRequired] after parsing to
+// enforce it. A long option whose Val is one of these characters also satisfies the requirement.
+func (g *Getopt) Require(c ...rune) {
+	g.required = append(g.required, c...)
+}
+
+// SetSingleton marks the options with the given characters as allowed at most once. Unlike the checks made after
+// parsing, this is enforced during the scan: when one of these options is given again, [Getopt.Getopt] returns a
+// [DuplicateUseError] instead of the option, and the error's position is that of the repeated option. As with
+// [Getopt.Require], a long option whose Val is one of these characters counts as the corresponding option.
+func (g *Getopt) SetSingleton(vals ...rune) {
+	g.singleton = append(g.singleton, vals...)
+}
+
+// CheckRequired returns a [MissingRequiredOptionError] if any required option has not been given. Required options
+// are long options whose Required field is true and short options registered with [Getopt.Require]. Call it after the
+// parse loop is finished. Anything after a '--' terminator is not an option, so it does not satisfy a requirement.
+func (g *Getopt) CheckRequired() error {
+	var missing []string
+	covered := map[rune]bool{}
+	for i, o := range g.longOptions {
+		if !o.Required {
+			continue
+		}
+		if o.Flag == nil {
+			covered[o.Val] = true
+		}
+		if !g.seenLongOption(i) {
+			missing = append(missing, argumentTerminator+o.Name)
+		}
+	}
+	for _, c := range g.required {
+		if !covered[c] && !g.seen(c) {
+			covered[c] = true
+			missing = append(missing, dash+string(c))
+		}
+	}
+	if len(missing) > 0 {
+		return MissingRequiredOptionError{Names: missing}
+	}
+	return nil
+}
+
+// dependency records that the option with character needy may only be given along with the option with character
+// needed.
+type dependency struct {
+	needy  rune
+	needed rune
+}
+
+// AddDependency records that the option with character needy may only be given if the option with character needed is
+// also given. Use [Getopt.CheckDependencies] after parsing to enforce it. As with [Getopt.Require], a long option whose
+// Val is one of these characters counts as the corresponding option.
+func (g *Getopt) AddDependency(needy rune, needed rune) {
+	g.dependencies = append(g.dependencies, dependency{needy: needy, needed: needed})
+}
+
+// CheckDependencies returns a [MissingDependencyError] for the first dependency, in the order they were added, whose
+// needy option was given without its needed option. Call it after the parse loop is finished.
+func (g *Getopt) CheckDependencies() error {
+	for _, d := range g.dependencies {
+		if g.seen(d.needy) && !g.seen(d.needed) {
+			return MissingDependencyError{Needy: d.needy, Needed: d.needed}
+		}
+	}
+	return nil
+}
+
+// SetMinOperands sets the fewest non-option arguments the program accepts. Use [Getopt.CheckOperands] after parsing to
+// enforce it. The default is 0.
+func (g *Getopt) SetMinOperands(n int) {
+	g.minOperands = n
+}
+
+// SetMaxOperands sets the most non-option arguments the program accepts. Use [Getopt.CheckOperands] after parsing to
+// enforce it. A negative n, the default, means there's no limit.
+func (g *Getopt) SetMaxOperands(n int) {
+	g.maxOperands = n
+}
+
+// CheckOperands returns a [TooFewOperandsError] or [TooManyOperandsError] if the number of non-option arguments is
+// outside the limits set with [Getopt.SetMinOperands] and [Getopt.SetMaxOperands]. The arguments counted are those
+// returned by [Getopt.Operands]. Call it after the parse loop is finished.
+func (g *Getopt) CheckOperands() error {
+	got := len(g.Operands())
+	if got < g.minOperands {
+		return TooFewOperandsError{Got: got, Min: g.minOperands}
+	}
+	if g.maxOperands >= 0 && got > g.maxOperands {
+		return TooManyOperandsError{Got: got, Max: g.maxOperands}
+	}
+	return nil
+}
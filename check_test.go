@@ -0,0 +1,159 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+// parseAll runs the parse loop to completion, ignoring the results.
+func parseAll(gopt *Getopt) {
+	for range gopt.All() { //revive:disable-line:empty-block
+	}
+}
+
+var _ = Describe("CheckRequired", func() {
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o', Required: true},
+		{Name: "verbose", Val: 'v'},
+	}
+
+	It("accepts required options given by name", func() {
+		gopt := NewLong([]string{"program", "--output", "x"}, "o:vq", longopts)
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(Succeed())
+	})
+
+	It("accepts required options given by short equivalent", func() {
+		gopt := NewLong([]string{"program", "-ox"}, "o:vq", longopts)
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(Succeed())
+	})
+
+	It("reports missing options", func() {
+		gopt := NewLong([]string{"program", "-v"}, "o:vq", longopts)
+		gopt.Require('q', 'v')
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(MatchError(MissingRequiredOptionError{Names: []string{"--output", "-q"}}))
+		Expect(gopt.CheckRequired()).To(MatchError("missing required options: '--output' '-q'"))
+	})
+
+	It("does not count options after the terminator", func() {
+		gopt := NewLong([]string{"program", "-v", "--", "--output", "x"}, "o:v", longopts)
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(MatchError("missing required option: '--output'"))
+		Expect(gopt.Remaining()).To(HaveExactElements("--output", "x"))
+	})
+
+	It("reports a short requirement once when a long option covers it", func() {
+		gopt := NewLong([]string{"program"}, "o:v", longopts)
+		gopt.Require('o')
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(MatchError(MissingRequiredOptionError{Names: []string{"--output"}}))
+	})
+
+	It("accepts a long option for a required short option", func() {
+		gopt := NewLong([]string{"program", "--verbose", "-o", "x"}, "o:v", longopts)
+		gopt.Require('v')
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(Succeed())
+	})
+})
+
+var _ = Describe("CheckDependencies", func() {
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+		{Name: "output-format", HasArg: RequiredArgument, Val: 'f'},
+	}
+
+	It("accepts satisfied dependencies", func() {
+		gopt := NewLong([]string{"program", "--output-format=json", "-o", "x"}, "o:f:v", longopts)
+		gopt.AddDependency('f', 'o')
+		parseAll(gopt)
+		Expect(gopt.CheckDependencies()).To(Succeed())
+	})
+
+	It("ignores dependencies of options not given", func() {
+		gopt := NewLong([]string{"program", "-v"}, "o:f:v", longopts)
+		gopt.AddDependency('f', 'o')
+		parseAll(gopt)
+		Expect(gopt.CheckDependencies()).To(Succeed())
+	})
+
+	It("reports the first missing dependency", func() {
+		gopt := NewLong([]string{"program", "-v", "--output-format", "json"}, "o:f:vq", longopts)
+		gopt.AddDependency('v', 'q')
+		gopt.AddDependency('f', 'o')
+		parseAll(gopt)
+		Expect(gopt.CheckDependencies()).To(MatchError(MissingDependencyError{Needy: 'v', Needed: 'q'}))
+		Expect(gopt.CheckDependencies()).To(MatchError("option '-v' requires option '-q'"))
+	})
+})
+
+var _ = Describe("SetSingleton", func() {
+	longopts := []Option{{Name: "output", HasArg: RequiredArgument, Val: 'o'}}
+
+	It("allows one use", func() {
+		gopt := NewLong([]string{"program", "-o", "x", "-v", "-v"}, "o:v", longopts)
+		gopt.SetSingleton('o')
+		for _, err := range gopt.All() {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("reports the second use at its position", func() {
+		gopt := NewLong([]string{"program", "--output=x", "y", "-vo", "z", "w"}, "o:v", longopts)
+		gopt.SetSingleton('o')
+		Expect(gopt.Getopt()).To(HaveField("C", 'o'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		opt, err := gopt.Getopt()
+		Expect(opt).To(BeNil())
+		Expect(err).To(MatchError(DuplicateUseError{Val: 'o'}))
+		Expect(err).To(MatchError("option '-o' may be given only once"))
+		Expect(err).To(MatchError(PositionedError{Index: 3, Err: DuplicateUseError{Val: 'o'}}))
+		Expect(gopt.Count('o')).To(Equal(1))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("y", "w"))
+	})
+
+	It("counts collapsed repeats", func() {
+		gopt := New([]string{"program", "-vv"}, "v")
+		gopt.SetCollapseRepeats(true)
+		gopt.SetSingleton('v')
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(DuplicateUseError{Val: 'v'}))
+	})
+})
+
+var _ = Describe("CheckOperands", func() {
+	DescribeTable("enforces limits",
+		func(args []string, lower, upper int, expected error) {
+			gopt := New(append([]string{"program", "-a"}, args...), "a")
+			gopt.SetMinOperands(lower)
+			gopt.SetMaxOperands(upper)
+			parseAll(gopt)
+			if expected == nil {
+				Expect(gopt.CheckOperands()).To(Succeed())
+			} else {
+				Expect(gopt.CheckOperands()).To(MatchError(expected))
+			}
+		},
+		Entry("within limits", []string{"x", "y"}, 2, 2, nil),
+		Entry("no limit", []string{"x", "y", "z"}, 0, -1, nil),
+		Entry("too few", []string{"x"}, 2, 2, TooFewOperandsError{Got: 1, Min: 2}),
+		Entry("too many", []string{"x", "y", "z"}, 2, 2, TooManyOperandsError{Got: 3, Max: 2}),
+		Entry("none allowed", []string{"x"}, 0, 0, TooManyOperandsError{Got: 1, Max: 0}),
+		Entry("counting after the terminator", []string{"x", "--", "-a"}, 0, 1, TooManyOperandsError{Got: 2, Max: 1}),
+	)
+
+	It("has no limits by default", func() {
+		gopt := New([]string{"program", "x", "y"}, "")
+		parseAll(gopt)
+		Expect(gopt.CheckOperands()).To(Succeed())
+	})
+
+	It("describes the problem", func() {
+		Expect(TooFewOperandsError{Got: 1, Min: 2}).To(MatchError("too few arguments: got 1, need at least 2"))
+		Expect(TooManyOperandsError{Got: 3, Max: 2}).To(MatchError("too many arguments: got 3, allowed at most 2"))
+	})
+})
@@ -0,0 +1,32 @@
+package getopt
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns an independent copy of the parser, including its position in the scan. The copy can be used to explore
+// an alternative parse, such as speculatively detecting a subcommand, without disturbing the original: the clone has
+// its own copy of Args, which it may permute freely, and its own option definitions and bookkeeping.
+//
+// Variables outside the parser are shared, not copied. The clone still stores through the Flag pointers of long
+// options and appends to the slices registered with [Getopt.Accumulate] or [Option.Accumulate].
+func (g *Getopt) Clone() *Getopt {
+	c := *g
+	c.Args = slices.Clone(g.Args)
+	c.shortOptions.Opts = maps.Clone(g.shortOptions.Opts)
+	c.longOptions = slices.Clone(g.longOptions)
+	for i := range c.longOptions {
+		c.longOptions[i].Aliases = slices.Clone(c.longOptions[i].Aliases)
+	}
+	c.counts = maps.Clone(g.counts)
+	c.seenLong = maps.Clone(g.seenLong)
+	c.required = slices.Clone(g.required)
+	c.singleton = slices.Clone(g.singleton)
+	c.inOrder = slices.Clone(g.inOrder)
+	c.dependencies = slices.Clone(g.dependencies)
+	c.accumulators = maps.Clone(g.accumulators)
+	c.transforms = maps.Clone(g.transforms)
+	c.introducers = slices.Clone(g.introducers)
+	return &c
+}
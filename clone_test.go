@@ -0,0 +1,43 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Clone", func() {
+	It("leaves the original undisturbed", func() {
+		gopt := NewLong([]string{"program", "-a", "x", "-bc", "y", "--delta", "z"}, "abc", []Option{
+			{Name: "delta", Val: 'd'},
+		})
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+
+		clone := gopt.Clone()
+		Expect(clone.Getopt()).To(HaveField("C", 'c'))
+		Expect(clone.Getopt()).To(HaveField("C", 'd'))
+		Expect(clone.Getopt()).To(BeNil())
+		Expect(clone.Remaining()).To(HaveExactElements("x", "y", "z"))
+		Expect(clone.Count('d')).To(Equal(1))
+
+		Expect(gopt.Args).To(HaveExactElements("program", "-a", "x", "-bc", "y", "--delta", "z"))
+		Expect(gopt.Count('d')).To(Equal(0))
+		Expect(gopt.Count('b')).To(Equal(1))
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'd'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y", "z"))
+	})
+
+	It("copies settings", func() {
+		gopt := New([]string{"program", "x", "-a"}, "a")
+		gopt.StopAtFirstNonOption(true)
+		gopt.Require('a')
+		clone := gopt.Clone()
+		gopt.StopAtFirstNonOption(false)
+
+		Expect(clone.Getopt()).To(BeNil())
+		Expect(clone.CheckRequired()).To(MatchError(MissingRequiredOptionError{Names: []string{"-a"}}))
+	})
+})
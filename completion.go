@@ -0,0 +1,144 @@
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completionFunctionName returns the name of the shell function that completes arguments for progName. Characters
+// that aren't valid in a function name are replaced by underscores.
+func completionFunctionName(progName string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, progName)
+}
+
+// BashCompletion returns a bash script that defines a completion function for progName and registers it with
+// "complete". Source the script in bash to complete the program's options.
+//
+// The function offers every short option and every long option name, including the "no-" forms of negatable options,
+// when the word being completed starts with '-'. Long options that require an argument are offered as "--name=" so
+// the argument can be typed next. After a short or long option that requires its argument in the next word, and for
+// non-option arguments, bash's default completion applies.
+func BashCompletion(progName string, opts string, longOptions []Option) string {
+	info, _ := parseShortOptionSpec(opts)
+
+	var words, takesArg []string
+	for _, c := range shortOptionLetters(opts, info) {
+		words = append(words, dash+string(c))
+		if shortDisposition(info, c) == RequiredArgument {
+			takesArg = append(takesArg, dash+string(c))
+		}
+	}
+	for _, n := range longNames(longOptions) {
+		name := argumentTerminator + n.name
+		if longOptions[n.index].HasArg == RequiredArgument && !n.negated {
+			takesArg = append(takesArg, name)
+			name += "="
+		}
+		words = append(words, name)
+	}
+
+	fn := completionFunctionName(progName)
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%s() {\n", fn)
+	_, _ = b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	_, _ = b.WriteString("    local prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	if len(takesArg) > 0 {
+		_, _ = b.WriteString("    case \"${prev}\" in\n")
+		_, _ = fmt.Fprintf(&b, "        %s)\n", strings.Join(takesArg, "|"))
+		_, _ = b.WriteString("            return\n")
+		_, _ = b.WriteString("            ;;\n")
+		_, _ = b.WriteString("    esac\n")
+	}
+	_, _ = b.WriteString("    if [[ \"${cur}\" == -* ]]; then\n")
+	_, _ = fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W '%s' -- \"${cur}\"))\n", strings.Join(words, " "))
+	_, _ = b.WriteString("        if [[ \"${COMPREPLY[0]}\" == *= ]]; then\n")
+	_, _ = b.WriteString("            compopt -o nospace\n")
+	_, _ = b.WriteString("        fi\n")
+	_, _ = b.WriteString("    fi\n")
+	_, _ = b.WriteString("}\n")
+	_, _ = fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, progName)
+	return b.String()
+}
+
+// zshArgSuffixes returns the text that follows a short option, a long option, and the description in a zsh _arguments
+// specification for an option with the given disposition.
+func zshArgSuffixes(hasArg ArgumentDisposition) (short, long, arg string) {
+	switch hasArg {
+	case RequiredArgument:
+		return "+", "=", ":value:"
+	case OptionalArgument:
+		return "-", "=-", "::value:"
+	default:
+		return "", "", ""
+	}
+}
+
+// zshDescription returns the bracketed description for a zsh _arguments specification, escaping characters that have
+// special meaning there. It returns an empty string when there's no description.
+func zshDescription(desc string) string {
+	if desc == "" {
+		return ""
+	}
+	desc = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`).Replace(desc)
+	return "[" + desc + "]"
+}
+
+// shellQuote wraps s in single quotes for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ZshCompletion returns a zsh completion function for progName, written with _arguments. Save it as a file named
+// "_progName" in a directory on $fpath to have zsh load it automatically.
+//
+// Each option's Description is shown next to it. Options that take an argument render as "--name=[desc]:value:", and
+// those that don't render as "--name[desc]". A long option whose Flag is nil and whose Val is a short option letter
+// with the same argument disposition is grouped with that short option, so zsh shows the two together and doesn't
+// offer one after the other has been given. The "no-" forms of negatable options are listed separately. Non-option
+// arguments complete as files.
+func ZshCompletion(progName string, opts string, longOptions []Option) string {
+	info, _ := parseShortOptionSpec(opts)
+
+	var specs []string
+	paired := map[rune]bool{}
+	for _, o := range longOptions {
+		shortSuffix, longSuffix, argSuffix := zshArgSuffixes(o.HasArg)
+		desc := zshDescription(o.Description) + argSuffix
+		long := argumentTerminator + o.Name
+		if o.Flag == nil && info.HasOpt(o.Val) && !paired[o.Val] && shortDisposition(info, o.Val) == o.HasArg {
+			paired[o.Val] = true
+			short := dash + string(o.Val)
+			specs = append(specs, fmt.Sprintf("'(%s %s)'{%s%s,%s%s}%s",
+				short, long, short, shortSuffix, long, longSuffix, shellQuote(desc)))
+		} else {
+			specs = append(specs, shellQuote(long+longSuffix+desc))
+		}
+		if o.Negatable {
+			specs = append(specs, shellQuote(argumentTerminator+negationPrefix+o.Name+zshDescription(o.Description)))
+		}
+	}
+	for _, c := range shortOptionLetters(opts, info) {
+		if paired[c] {
+			continue
+		}
+		shortSuffix, _, argSuffix := zshArgSuffixes(shortDisposition(info, c))
+		specs = append(specs, shellQuote(dash+string(c)+shortSuffix+argSuffix))
+	}
+	specs = append(specs, shellQuote("*:argument:_files"))
+
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "#compdef %s\n\n", progName)
+	_, _ = b.WriteString("_arguments -s")
+	for _, spec := range specs {
+		_, _ = fmt.Fprintf(&b, " \\\n    %s", spec)
+	}
+	_, _ = b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("BashCompletion", func() {
+	It("lists all options", func() {
+		script := BashCompletion("my-tool", "vo:c::", []Option{
+			{Name: "verbose", Val: 'v'},
+			{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+			{Name: "color", HasArg: OptionalArgument, Val: 'c', Negatable: true},
+		})
+		Expect(script).To(Equal(`_my_tool() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    case "${prev}" in
+        -o|--output)
+            return
+            ;;
+    esac
+    if [[ "${cur}" == -* ]]; then
+        COMPREPLY=($(compgen -W '-v -o -c --verbose --output= --color --no-color' -- "${cur}"))
+        if [[ "${COMPREPLY[0]}" == *= ]]; then
+            compopt -o nospace
+        fi
+    fi
+}
+complete -o default -F _my_tool my-tool
+`))
+	})
+
+	It("omits the argument check when no option needs one", func() {
+		script := BashCompletion("prog", "ab", nil)
+		Expect(script).NotTo(ContainSubstring("case"))
+		Expect(script).To(ContainSubstring("compgen -W '-a -b'"))
+	})
+})
+
+var _ = Describe("ZshCompletion", func() {
+	It("describes all options", func() {
+		script := ZshCompletion("prog", "vo:c::xW;", []Option{
+			{Name: "verbose", Val: 'v', Description: "print more"},
+			{Name: "output", HasArg: RequiredArgument, Val: 'o', Description: "write to [file]"},
+			{Name: "color", HasArg: RequiredArgument, Val: 'c', Description: "it's colorful: yes"},
+			{Name: "level", HasArg: OptionalArgument, Negatable: true},
+		})
+		Expect(script).To(Equal(`#compdef prog
+
+_arguments -s \
+    '(-v --verbose)'{-v,--verbose}'[print more]' \
+    '(-o --output)'{-o+,--output=}'[write to \[file\]]:value:' \
+    '--color=[it'\''s colorful\: yes]:value:' \
+    '--level=-::value:' \
+    '--no-level' \
+    '-c-::value:' \
+    '-x' \
+    '-W+:value:' \
+    '*:argument:_files'
+`))
+	})
+})
@@ -0,0 +1,37 @@
+package getopt_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Concurrent introspection", func() {
+	It("is safe after parsing", func() {
+		gopt := NewLong([]string{"program", "x", "-a", "--bravo", "y", "--", "z"}, "a", []Option{
+			{Name: "bravo", Val: 'b', Required: true},
+		})
+		gopt.SetMaxOperands(3)
+		parseAll(gopt)
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(gopt.Optind()).To(Equal(4))
+				Expect(gopt.Remaining()).To(HaveExactElements("x", "y", "z"))
+				Expect(gopt.Operands()).To(HaveExactElements("x", "y", "z"))
+				Expect(gopt.Count('a')).To(Equal(1))
+				Expect(gopt.TerminatorIndex()).To(Equal(5))
+				Expect(gopt.CheckRequired()).To(Succeed())
+				Expect(gopt.CheckOperands()).To(Succeed())
+				Expect(gopt.DebugState()).NotTo(BeEmpty())
+			}()
+		}
+		wg.Wait()
+	})
+})
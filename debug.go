@@ -0,0 +1,23 @@
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DebugState returns a description of the parser's scanning state for use while debugging, with one item per line:
+// the scan position, the unscanned remainder of the current option cluster, the bounds of the non-options skipped so
+// far, and each element of Args with its index. Comparing the output before and after a call to [Getopt.Getopt] shows
+// how Args was permuted. The format is meant for people and may change.
+func (g *Getopt) DebugState() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "optind: %d\n", g.optind)
+	_, _ = fmt.Fprintf(&b, "nextChar: %q\n", g.nextChar)
+	_, _ = fmt.Fprintf(&b, "firstNonopt: %d\n", g.firstNonopt)
+	_, _ = fmt.Fprintf(&b, "lastNonopt: %d\n", g.lastNonopt)
+	_, _ = fmt.Fprintf(&b, "Args:\n")
+	for i, arg := range g.Args {
+		_, _ = fmt.Fprintf(&b, "  [%d] %q\n", i, arg)
+	}
+	return b.String()
+}
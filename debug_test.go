@@ -0,0 +1,45 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("DebugState", func() {
+	It("describes the scan", func() {
+		gopt := New([]string{"program", "x", "-ab", "y", "-c"}, "abc")
+		Expect(gopt.DebugState()).To(Equal("" +
+			"optind: 1\n" +
+			"nextChar: \"\"\n" +
+			"firstNonopt: 1\n" +
+			"lastNonopt: 1\n" +
+			"Args:\n" +
+			"  [0] \"program\"\n" +
+			"  [1] \"x\"\n" +
+			"  [2] \"-ab\"\n" +
+			"  [3] \"y\"\n" +
+			"  [4] \"-c\"\n"))
+
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(gopt.DebugState()).To(Equal("" +
+			"optind: 5\n" +
+			"nextChar: \"\"\n" +
+			"firstNonopt: 2\n" +
+			"lastNonopt: 4\n" +
+			"Args:\n" +
+			"  [0] \"program\"\n" +
+			"  [1] \"-ab\"\n" +
+			"  [2] \"x\"\n" +
+			"  [3] \"y\"\n" +
+			"  [4] \"-c\"\n"))
+	})
+
+	It("shows the rest of a cluster", func() {
+		gopt := New([]string{"program", "-abc"}, "abc")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.DebugState()).To(ContainSubstring("nextChar: \"bc\"\n"))
+	})
+})
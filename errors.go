@@ -1,49 +1,290 @@
 package getopt
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// These sentinel errors classify the errors returned while parsing. Use them with [errors.Is] to handle a whole
+// category of error without regard to the specific option involved.
+var (
+	ErrAmbiguous          = errors.New("ambiguous option")     // Matches [AmbiguousOptionError].
+	ErrUnrecognized       = errors.New("unrecognized option")  // Matches [UnrecognizedOptionError].
+	ErrArgumentNotAllowed = errors.New("argument not allowed") // Matches [ArgumentNotAllowedError].
+	ErrArgumentRequired   = errors.New("argument required")    // Matches [ArgumentRequiredError].
+	ErrInvalidArgument    = errors.New("invalid argument")     // Matches [InvalidArgumentError].
+	ErrAttachedArgument   = errors.New("attached argument")    // Matches [AttachedArgumentError].
+)
+
+// These sentinel errors are returned for the options handled automatically by [Getopt.SetAutoHelp] and
+// [Getopt.SetAutoVersion]. They report requests, not problems, so a program that receives one should exit
+// successfully.
+var (
+	ErrHelpRequested    = errors.New("help requested")
+	ErrVersionRequested = errors.New("version requested")
+)
+
+// Each error type below has a Prefix field that holds the text that introduced the offending option on the command
+// line: "-" for short options, "--" for long options, or "-W " for long options given through the -W extension. (When
+// parsing with [Getopt.GetoptLongOnly], long options may also be introduced by "-".) Callers can inspect Prefix to
+// distinguish short and long options without parsing the error message.
 
 // AmbiguousOptionError is returned when there is no exact match for Option, but more than one abbreviated match, which
-// are given in Candidates.
+// are given in Candidates. The candidates are sorted, so the error doesn't depend on the order the options were
+// defined in.
 type AmbiguousOptionError struct {
 	Option     string
 	Candidates []string
-	prefix     string
+	Prefix     string
 }
 
 func (e AmbiguousOptionError) Error() string {
-	result := fmt.Sprintf("option '%s%s' is ambiguous; possibilities:", e.prefix, e.Option)
+	result := fmt.Sprintf("option '%s%s' is ambiguous; possibilities:", e.Prefix, e.Option)
 	for _, opt := range e.Candidates {
-		result = result + fmt.Sprintf(" '%s%s'", e.prefix, opt)
+		result = result + fmt.Sprintf(" '%s%s'", e.Prefix, opt)
 	}
 	return result
 }
 
+// Is reports whether target is [ErrAmbiguous].
+func (e AmbiguousOptionError) Is(target error) bool {
+	return target == ErrAmbiguous
+}
+
 // UnrecognizedOptionError is returned when Option on the command line is not a recogized option.
 type UnrecognizedOptionError struct {
 	Option string
-	prefix string
+	Prefix string
 }
 
 func (e UnrecognizedOptionError) Error() string {
-	return fmt.Sprintf("unrecognized option '%s%s'", e.prefix, e.Option)
+	return fmt.Sprintf("unrecognized option '%s%s'", e.Prefix, e.Option)
 }
 
-// ArgumentNotAllowedError is returned when Option does not accept arguments but one is provided anyway.
+// Is reports whether target is [ErrUnrecognized].
+func (e UnrecognizedOptionError) Is(target error) bool {
+	return target == ErrUnrecognized
+}
+
+// ArgumentNotAllowedError is returned when Option does not accept arguments but one is provided anyway. For a long
+// option, Option is the full name that matched, such as "sample" when "--sam=x" was given.
 type ArgumentNotAllowedError struct {
 	Option string
-	prefix string
+	Prefix string
 }
 
 func (e ArgumentNotAllowedError) Error() string {
-	return fmt.Sprintf("option '%s%s' doesn't allow an argument", e.prefix, e.Option)
+	return fmt.Sprintf("option '%s%s' doesn't allow an argument", e.Prefix, e.Option)
+}
+
+// Is reports whether target is [ErrArgumentNotAllowed].
+func (e ArgumentNotAllowedError) Is(target error) bool {
+	return target == ErrArgumentNotAllowed
 }
 
-// ArgumentRequiredError is returned when Option expects an argument and none is given.
+// ArgumentRequiredError is returned when Option expects an argument and none is given. For a long option, Option is the
+// full name that matched, as with [ArgumentNotAllowedError].
 type ArgumentRequiredError struct {
 	Option string
-	prefix string
+	Prefix string
 }
 
 func (e ArgumentRequiredError) Error() string {
-	return fmt.Sprintf("option '%s%s' requires an argument", e.prefix, e.Option)
+	return fmt.Sprintf("option '%s%s' requires an argument", e.Prefix, e.Option)
+}
+
+// Is reports whether target is [ErrArgumentRequired].
+func (e ArgumentRequiredError) Is(target error) bool {
+	return target == ErrArgumentRequired
+}
+
+// AttachedArgumentError is returned when Option, which was marked with [Option.SeparateArg], is given the argument Arg
+// in the same element of the command line, as in "-ofile" or "--output=file", instead of in the following element.
+type AttachedArgumentError struct {
+	Option string
+	Prefix string
+	Arg    string
+}
+
+func (e AttachedArgumentError) Error() string {
+	return fmt.Sprintf("option '%s%s' requires its argument as a separate word", e.Prefix, e.Option)
+}
+
+// Is reports whether target is [ErrAttachedArgument].
+func (e AttachedArgumentError) Is(target error) bool {
+	return target == ErrAttachedArgument
+}
+
+// WOptionError is returned when the -W extension is used without the name of a long option, either because nothing
+// follows it or because the name is empty. Prefix is the option itself, normally "-W". It matches
+// [ErrArgumentRequired].
+type WOptionError struct {
+	Prefix string
+}
+
+func (e WOptionError) Error() string {
+	return fmt.Sprintf("option '%s' requires a long option name", e.Prefix)
+}
+
+// Is reports whether target is [ErrArgumentRequired].
+func (e WOptionError) Is(target error) bool {
+	return target == ErrArgumentRequired
+}
+
+// InvalidArgumentError is returned when the argument Arg given for Option can't be used. Err describes the problem.
+type InvalidArgumentError struct {
+	Option string
+	Prefix string
+	Arg    string
+	Err    error
+}
+
+func (e InvalidArgumentError) Error() string {
+	return fmt.Sprintf("invalid argument '%s' for '%s%s': %v", e.Arg, e.Prefix, e.Option, e.Err)
+}
+
+// Is reports whether target is [ErrInvalidArgument].
+func (e InvalidArgumentError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// Unwrap returns Err.
+func (e InvalidArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// PositionedError wraps each error returned by [Getopt.Getopt] and its variants to tell where on the command line the
+// problem is. Index is the position in Args, as originally given before any permutation, of the element holding the
+// offending option or, for a long option given as a separate argument to -W, of that argument. Its message is that of
+// Err, and [errors.Is] and [errors.As] see through it to Err.
+type PositionedError struct {
+	Index int
+	Err   error
+}
+
+func (e PositionedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns Err.
+func (e PositionedError) Unwrap() error {
+	return e.Err
+}
+
+// DuplicateOptionError is returned when the option called Name is defined more than once. Prefix is "-" for short
+// options and "--" for long options.
+type DuplicateOptionError struct {
+	Name   string
+	Prefix string
+}
+
+func (e DuplicateOptionError) Error() string {
+	return fmt.Sprintf("option '%s%s' is defined more than once", e.Prefix, e.Name)
+}
+
+// SpecSyntaxError is returned by [NewStrict] when the short option specification is malformed, such as when it has
+// more than two colons after an option letter or a semicolon that doesn't follow 'W'. Pos is the index, in runes, of
+// the offending character in the specification, and Message describes the problem.
+type SpecSyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e SpecSyntaxError) Error() string {
+	return fmt.Sprintf("invalid option specification at position %d: %s", e.Pos, e.Message)
+}
+
+// EmptyOptionNameError is returned when the long option at Index in a list of options has no name.
+type EmptyOptionNameError struct {
+	Index int
+}
+
+func (e EmptyOptionNameError) Error() string {
+	return fmt.Sprintf("long option at index %d has an empty name", e.Index)
+}
+
+// MissingRequiredOptionError is returned by [Getopt.CheckRequired] when required options were not given. Names lists
+// the missing options, each including its "-" or "--" prefix.
+type MissingRequiredOptionError struct {
+	Names []string
+}
+
+func (e MissingRequiredOptionError) Error() string {
+	result := "missing required option"
+	if len(e.Names) > 1 {
+		result += "s"
+	}
+	result += ":"
+	for _, name := range e.Names {
+		result = result + fmt.Sprintf(" '%s'", name)
+	}
+	return result
+}
+
+// MissingDependencyError is returned by [Getopt.CheckDependencies] when the option with character Needy was given
+// without the option with character Needed.
+type MissingDependencyError struct {
+	Needy  rune
+	Needed rune
+}
+
+func (e MissingDependencyError) Error() string {
+	return fmt.Sprintf("option '%s%c' requires option '%s%c'", dash, e.Needy, dash, e.Needed)
+}
+
+// DuplicateUseError is returned when the option with character Val, which was marked with [Getopt.SetSingleton], is
+// given a second time.
+type DuplicateUseError struct {
+	Val rune
+}
+
+func (e DuplicateUseError) Error() string {
+	return fmt.Sprintf("option '%s%c' may be given only once", dash, e.Val)
+}
+
+// InOrderRuneError is returned by [Getopt.SetInOrderRune] when Rune can't mark non-option arguments because it could
+// also be the character of an option.
+type InOrderRuneError struct {
+	Rune rune
+}
+
+func (e InOrderRuneError) Error() string {
+	return fmt.Sprintf("in-order marker %q conflicts with an option", e.Rune)
+}
+
+// TooFewOperandsError is returned by [Getopt.CheckOperands] when there are Got non-option arguments but at least Min
+// are required.
+type TooFewOperandsError struct {
+	Got int
+	Min int
+}
+
+func (e TooFewOperandsError) Error() string {
+	return fmt.Sprintf("too few arguments: got %d, need at least %d", e.Got, e.Min)
+}
+
+// TooManyOperandsError is returned by [Getopt.CheckOperands] when there are Got non-option arguments but at most Max
+// are allowed.
+type TooManyOperandsError struct {
+	Got int
+	Max int
+}
+
+func (e TooManyOperandsError) Error() string {
+	return fmt.Sprintf("too many arguments: got %d, allowed at most %d", e.Got, e.Max)
+}
+
+// OrderingConflictError is returned by [Getopt.SetOrdering] when the Requested ordering disagrees with the Spec
+// ordering selected by the '+' or '-' prefix of the short option specification.
+type OrderingConflictError struct {
+	Requested Ordering
+	Spec      Ordering
+}
+
+func (e OrderingConflictError) Error() string {
+	prefix := "+"
+	if e.Spec == ReturnInOrder {
+		prefix = "-"
+	}
+	return fmt.Sprintf("explicit ordering conflicts with '%s' prefix of option specification", prefix)
 }
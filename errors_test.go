@@ -1,6 +1,7 @@
 package getopt_test
 
 import (
+	"errors"
 	"fmt"
 
 	. "github.com/rkennedy/go-getopt"
@@ -64,3 +65,21 @@ func ExampleGetopt_Optind() {
 	// Got argument: f1
 	// Remaining arguments: [f2 f3]
 }
+
+func ExampleUnrecognizedOptionError_Prefix() {
+	longopts := []Option{
+		{Name: "alpha", HasArg: NoArgument, Val: 'a'},
+	}
+	argv := []string{"program", "-x", "--bravo"}
+
+	gopt := NewLong(argv, "a", longopts)
+	for _, err := gopt.Getopt(); err != nil; _, err = gopt.Getopt() {
+		var unrecog UnrecognizedOptionError
+		if errors.As(err, &unrecog) {
+			_, _ = fmt.Printf("%q %s\n", unrecog.Prefix, unrecog.Option)
+		}
+	}
+	// Output:
+	// "-" x
+	// "--" bravo
+}
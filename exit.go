@@ -0,0 +1,52 @@
+package getopt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// HandleError reports err the way GNU command-line tools do and returns the status the program should exit with. If
+// err is nil, it writes nothing and returns 0. Otherwise, it writes progName, a colon, and the error message to w,
+// followed by a newline. The result is 2, the traditional status for a usage error, if err is or wraps one of the error
+// types of this package, such as the errors returned by [Getopt.Getopt] or [Getopt.CheckRequired], and 1 for any other
+// error.
+//
+// If err is or wraps [ErrHelpRequested] or [ErrVersionRequested], the parser has already printed the help or version
+// text, so HandleError writes nothing and returns 0.
+//
+// A typical program calls it once after its parse loop:
+//
+//	if code := getopt.HandleError(os.Stderr, os.Args[0], err); code != 0 {
+//		os.Exit(code)
+//	}
+func HandleError(w io.Writer, progName string, err error) (exitCode int) {
+	if err == nil || errors.Is(err, ErrHelpRequested) || errors.Is(err, ErrVersionRequested) {
+		return 0
+	}
+	_, _ = fmt.Fprintf(w, "%s: %s\n", progName, err.Error())
+	if isPackageError(err) {
+		return 2
+	}
+	return 1
+}
+
+// isPackageError reports whether err or any error it wraps has one of the error types defined by this package. Errors
+// joined with [errors.Join], such as those from [Getopt.ParseCollect], are searched too.
+func isPackageError(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			return slices.ContainsFunc(joined.Unwrap(), isPackageError)
+		}
+		switch err.(type) {
+		case AmbiguousOptionError, UnrecognizedOptionError, ArgumentNotAllowedError, ArgumentRequiredError,
+			AttachedArgumentError, WOptionError, InvalidArgumentError, PositionedError, DuplicateOptionError,
+			SpecSyntaxError, EmptyOptionNameError, MissingRequiredOptionError, MissingDependencyError,
+			DuplicateUseError, InOrderRuneError, TooFewOperandsError, TooManyOperandsError, OrderingConflictError,
+			BindError, ResponseFileError, SplitArgsError:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package getopt_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("HandleError", func() {
+	It("ignores nil", func() {
+		var out strings.Builder
+		Expect(HandleError(&out, "program", nil)).To(Equal(0))
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	DescribeTable("exits successfully for help and version requests",
+		func(sentinel error) {
+			var out strings.Builder
+			Expect(HandleError(&out, "program", PositionedError{Index: 1, Err: sentinel})).To(Equal(0))
+			Expect(out.String()).To(BeEmpty())
+		},
+		Entry("help", ErrHelpRequested),
+		Entry("version", ErrVersionRequested),
+	)
+
+	It("reports parse errors as usage errors", func() {
+		gopt := New([]string{"program", "-x"}, "a")
+		_, err := gopt.Getopt()
+		var out strings.Builder
+		Expect(HandleError(&out, "program", err)).To(Equal(2))
+		Expect(out.String()).To(Equal("program: unrecognized option '-x'\n"))
+	})
+
+	It("reports wrapped errors of this package as usage errors", func() {
+		err := fmt.Errorf("parsing: %w", MissingRequiredOptionError{Names: []string{"--output"}})
+		var out strings.Builder
+		Expect(HandleError(&out, "program", err)).To(Equal(2))
+		Expect(out.String()).To(Equal("program: parsing: missing required option: '--output'\n"))
+	})
+
+	It("reports joined errors from ParseCollect as usage errors", func() {
+		_, err := New([]string{"program", "-x", "-y"}, "a").ParseCollect()
+		var out strings.Builder
+		Expect(HandleError(&out, "program", errors.Join(os.ErrNotExist, err))).To(Equal(2))
+		Expect(HandleError(&out, "program", errors.Join(os.ErrNotExist))).To(Equal(1))
+	})
+
+	It("reports other errors as failures", func() {
+		var out strings.Builder
+		Expect(HandleError(&out, "program", os.ErrNotExist)).To(Equal(1))
+		Expect(out.String()).To(Equal("program: file does not exist\n"))
+	})
+
+	It("doesn't treat sentinels as this package's error types", func() {
+		var out strings.Builder
+		Expect(HandleError(&out, "program", errors.New("argument required"))).To(Equal(1))
+	})
+})
+
+func ExampleHandleError() {
+	gopt := New([]string{"program", "-o"}, "o:")
+	_, err := gopt.Getopt()
+	code := HandleError(os.Stdout, "program", err)
+	_, _ = fmt.Println("exit status", code)
+	// Output:
+	// program: option '-o' requires an argument
+	// exit status 2
+}
@@ -0,0 +1,72 @@
+package getopt
+
+import (
+	"flag"
+	"strings"
+	"unicode/utf8"
+)
+
+// boolFlag is implemented by [flag.Value] types for flags that don't need an argument, as in the flag package.
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// BindFlagSet parses the parser's Args with options defined by the flags registered in fs, storing the value of each
+// option given with [flag.FlagSet.Set]. This lets a program keep its flag-based definitions while accepting GNU-style
+// options, with permutation, clustered short options, and abbreviated long options.
+//
+// A flag whose name is a single character becomes a short option, and any other flag becomes a long option described
+// by the flag's usage text. Boolean flags, those whose values have an IsBoolFlag method that returns true, take no
+// argument and are set to "true" when given; "-v" and "--verbose" are therefore equivalent to "-v=true" and
+// "-verbose=true" with the flag package. Every other flag requires an argument.
+//
+// The flags replace any options the parser was created with, but its other settings, such as the ordering, are kept.
+// Parsing stops at the first error, which is returned. If Set rejects a value, the error is an
+// [InvalidArgumentError] wrapping the error from Set. Afterward, [Getopt.Remaining] returns the non-option arguments.
+func (g *Getopt) BindFlagSet(fs *flag.FlagSet) error {
+	var opts strings.Builder
+	var longOptions []Option
+	fs.VisitAll(func(f *flag.Flag) {
+		hasArg := RequiredArgument
+		if b, ok := f.Value.(boolFlag); ok && b.IsBoolFlag() {
+			hasArg = NoArgument
+		}
+		if utf8.RuneCountInString(f.Name) == 1 {
+			_, _ = opts.WriteString(f.Name + strings.Repeat(":", int(hasArg)))
+			return
+		}
+		longOptions = append(longOptions, Option{
+			Name:        f.Name,
+			HasArg:      hasArg,
+			Description: f.Usage,
+		})
+	})
+
+	info, _ := parseShortOptionSpec(opts.String())
+	info.Ordering = g.shortOptions.Ordering
+	g.shortOptions = info
+	g.setLongOptions(longOptions)
+
+	for opt, err := range g.All() {
+		if err != nil {
+			return err
+		}
+		if g.isInOrder(opt) {
+			continue // A non-option argument in ReturnInOrder mode.
+		}
+		name, prefix := opt.name()
+		value := "true"
+		if opt.Arg != nil {
+			value = *opt.Arg
+		}
+		if err := fs.Set(name, value); err != nil {
+			return InvalidArgumentError{
+				Option: name,
+				Prefix: prefix,
+				Arg:    value,
+				Err:    err,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package getopt_test
+
+import (
+	"flag"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("BindFlagSet", func() {
+	var (
+		fs      *flag.FlagSet
+		verbose *bool
+		output  *string
+		level   *int
+		dryRun  *bool
+	)
+
+	BeforeEach(func() {
+		fs = flag.NewFlagSet("program", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		verbose = fs.Bool("v", false, "print more")
+		output = fs.String("o", "", "output file")
+		level = fs.Int("level", 0, "level")
+		dryRun = fs.Bool("dry-run", false, "don't do anything")
+	})
+
+	It("sets flags", func() {
+		gopt := New([]string{"program", "file", "-vofile", "--lev", "3", "--dry-run"}, "")
+		Expect(gopt.BindFlagSet(fs)).To(Succeed())
+		Expect(*verbose).To(BeTrue())
+		Expect(*output).To(Equal("file"))
+		Expect(*level).To(Equal(3))
+		Expect(*dryRun).To(BeTrue())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("keeps the parser's ordering", func() {
+		gopt := New([]string{"program", "-v", "file", "-o", "x"}, "+")
+		Expect(gopt.BindFlagSet(fs)).To(Succeed())
+		Expect(*verbose).To(BeTrue())
+		Expect(*output).To(BeEmpty())
+		Expect(gopt.Remaining()).To(HaveExactElements("file", "-o", "x"))
+	})
+
+	It("skips operands in order", func() {
+		gopt := New([]string{"program", "file", "-v"}, "-")
+		Expect(gopt.BindFlagSet(fs)).To(Succeed())
+		Expect(*verbose).To(BeTrue())
+	})
+
+	It("reports invalid values", func() {
+		gopt := New([]string{"program", "--level=high"}, "")
+		err := gopt.BindFlagSet(fs)
+		Expect(err).To(MatchError(ErrInvalidArgument))
+		Expect(err).To(MatchError(ContainSubstring("parse error")))
+		Expect(err).To(MatchError(ContainSubstring("invalid argument 'high' for '--level'")))
+	})
+
+	It("reports parse errors", func() {
+		gopt := New([]string{"program", "--bogus"}, "")
+		Expect(gopt.BindFlagSet(fs)).To(MatchError(ErrUnrecognized))
+	})
+})
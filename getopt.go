@@ -4,30 +4,39 @@
 //  1. There are no global variables. All operations are performed on a Getopt struct that maintains state between
 //     successive calls. To read an option's argument value, read [Opt.Arg] instead of optarg. To reset option-parsing,
 //     create a new [Getopt] struct instead of assign optreset.
-//  2. The opterr setting is permanently false. Errors are never printed anywhere by this library. Instead, errors are
-//     returned and the caller can choose what to do with them. The text of the errors corresponds to messages that
-//     would be printed by GNU getopt. The leading ':' in the option spec that controls error-reporting is accepted for
-//     compatibility, but it's ignored.
+//  2. The opterr setting is false by default. Errors are returned and the caller can choose what to do with them. The
+//     text of the errors corresponds to messages that would be printed by GNU getopt. Set [Getopt.Opterr] to also
+//     print them. The leading ':' in the option spec that controls error-reporting is accepted for compatibility, but
+//     it's ignored.
 //  3. A struct is returned instead of just the matched option character. The struct includes the option character, any
 //     value that would have been in optarg, as well as any value that would have been returned in the longindex
 //     argument to getopt_long.
 //  4. The optopt value is not used. Instead, the relevant unrecognized character or option name is available in the
 //     Option field of whatever error gets returned.
 //  5. The Flag and Val fields of Option have type rune, not int.
-//  6. The list of options and arguments cannot be changed in the middle of parsing. The argument list and option
-//     definition are set once at the start, and then you just call Getopt or GetoptLong with no parameters.
+//  6. The argument list and option definitions are set at the start, and then you just call Getopt or GetoptLong with
+//     no parameters. To change them later, call [Getopt.SetArgs] for a new argument list, [Getopt.Rewind] to scan the
+//     same arguments again, or [Getopt.Rebind] to replace the option definitions in the middle of parsing.
 //  7. The POSIXLY_CORRECT environment variable is ignored. The library runs as though the environment variable is never
 //     set. Use leading '+' or '-' characters in the option specification instead; see [Ordering] for more.
 package getopt
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
 	dash               = "-"
 	argumentTerminator = "--"
+	negationPrefix     = "no-"
 )
 
 // ArgumentDisposition is an enum specifying whether an option expects to be followed by an argument. Use it when
@@ -35,12 +44,30 @@ const (
 type ArgumentDisposition int
 
 // These [ArgumentDisposition] values are used for the HasArg field of [Option] values.
+//
+// An optional argument must be attached to its option, as in "-cvalue" or "--color=value". In every ordering mode,
+// "-c value" and "--color value" give the option no argument and leave "value" to be scanned as the next element, as
+// GNU getopt does. Only a required argument is ever taken from the following element.
 const (
 	NoArgument       ArgumentDisposition = iota // The option does not take an argument.
 	RequiredArgument                            // The option requires an argument.
 	OptionalArgument                            // The option takes an optional argument.
 )
 
+// String returns the name of the constant for d, such as "RequiredArgument".
+func (d ArgumentDisposition) String() string {
+	switch d {
+	case NoArgument:
+		return "NoArgument"
+	case RequiredArgument:
+		return "RequiredArgument"
+	case OptionalArgument:
+		return "OptionalArgument"
+	default:
+		return fmt.Sprintf("ArgumentDisposition(%d)", int(d))
+	}
+}
+
 // Option describes the long-named options requested by the application. The longopts argument to [NewLong] is a slice
 // of this type.
 //
@@ -55,10 +82,95 @@ type Option struct {
 	HasArg ArgumentDisposition
 	Flag   *rune
 	Val    rune
+
+	Description string // Help text shown for the option by [FormatUsage].
+	Group       string // Heading under which [FormatUsage] lists the option, such as "Output options".
+
+	// Negatable makes the option also match "--no-" followed by its name. Negatable options should not take an
+	// argument; instead, Opt.Arg points to "true" for the plain form and "false" for the negated form. For
+	// abbreviation purposes, the negated form is a separate name, so an abbreviation that could be either form is
+	// ambiguous.
+	Negatable bool
+
+	// Required means the option must be given. Use [Getopt.CheckRequired] after parsing to enforce it.
+	Required bool
+
+	// Aliases lists other names for the option. An alias behaves exactly like Name, and the option is reported with
+	// the same LongInd whichever name is used. Aliases must be given in full; they are never matched by an
+	// abbreviation. Because exact matches are checked before abbreviations, an alias takes precedence over another
+	// option whose name it abbreviates: with an alias "col" for "color", "--col" selects "color" even if there is also
+	// an option named "column".
+	Aliases []string
+
+	// Greedy makes the option consume every argument that follows it on the command line, which are returned in
+	// Opt.Args. This suits options such as "--exec cmd arg..." that run another program. Nothing after the option is
+	// scanned, not even a "--", which is collected like any other argument. The option's own argument, if it has
+	// one, is taken first as usual. Non-option arguments skipped before the option are still returned by
+	// [Getopt.Remaining], but permutation has no effect on the arguments collected. Greedy applies only when the
+	// option is given by name, not by the short option whose character is Val.
+	Greedy bool
+
+	// NArgs, if greater than 1, is the number of arguments the option takes, as with "--point X Y". HasArg should be
+	// RequiredArgument. Only the first argument may be attached, as in "--point=X Y" or "-pX Y"; the others are
+	// always taken from the following elements. All of them are returned in Opt.Args, and Opt.Arg points to the first.
+	// If too few elements remain, [Getopt.Getopt] returns an [ArgumentRequiredError]. Transform and Validate apply to
+	// each argument. The short option whose character is Val takes the same number of arguments, provided Flag is nil.
+	// NArgs has no effect on a Greedy option.
+	NArgs int
+
+	// SeparateArg requires the option's argument to be given in the element after the option, as in "-o file" or
+	// "--output file", and never attached to it, as in "-ofile" or "--output=file". An attached argument is rejected
+	// with an [AttachedArgumentError]. This suits options whose arguments may begin with letters that are also option
+	// characters, where "-ofile" would be easy to misread as a cluster. HasArg should be RequiredArgument. The short
+	// option whose character is Val is affected too, provided Flag is nil.
+	SeparateArg bool
+
+	// Validate, if not nil, checks each argument given for the option. If it returns an error, the option is not
+	// returned; [Getopt.Getopt] returns an [InvalidArgumentError] wrapping that error instead, and scanning continues
+	// with the next argument as it does after other errors. The short option whose character is Val is checked too,
+	// provided Flag is nil. Arguments supplied by the parser, such as the "true" of a Negatable option, aren't checked.
+	Validate func(arg string) error
+
+	// Deprecated, if not empty, marks the option as deprecated and explains what to use instead. Each time the option
+	// is given by name, the warning "warning: --name is deprecated: " followed by this text is written to the writer
+	// set with [Getopt.SetWarnWriter]. The option is otherwise handled as usual.
+	Deprecated string
+
+	// Transform, if not nil, rewrites each argument given for the option, such as to strip quotes, before the
+	// argument is stored in Opt.Arg. It runs before Validate, which checks the rewritten argument. As with Validate,
+	// the short option whose character is Val is transformed too, provided Flag is nil, and arguments supplied by the
+	// parser aren't transformed. It takes precedence over a function registered with [Getopt.SetTransform].
+	Transform func(arg string) string
+
+	// Accumulate, if not nil, points to a slice that receives the argument of each occurrence of the option. This is
+	// useful for options such as "--include" that may be given many times. The short option whose character is Val
+	// accumulates into the same slice, provided Flag is nil. The option is still returned from each call to
+	// [Getopt.Getopt] as usual.
+	Accumulate *[]string
 }
 
-// Ordering describes how to deal with options that follow non-option arguments. Values are documented here for
-// expository purposes but are not used by any client code.
+// NumberOption is the value of [Opt.C] for a numeric option, such as "-10", when numeric options are enabled by
+// including '#' in the short option specification. Opt.Arg holds the digits. This supports commands like head and
+// split, which accept a count written as "-5". Only elements consisting entirely of an option introducer followed by
+// ASCII digits are numeric options; with numeric options enabled, digit option letters can still be given in clusters
+// that include other letters, such as "-v1", but never alone.
+const NumberOption rune = '#'
+
+// InOrderOption is the default value of [Opt.C] for a non-option argument returned in [ReturnInOrder] mode. Use
+// [Getopt.SetInOrderRune] to choose another.
+const InOrderOption rune = 1
+
+// PlusOption is the value of [Opt.C] for an argument that begins with the character set by
+// [Getopt.SetPlusIntroducer], such as the "+%Y" of the date command. Opt.Arg holds the text after that character.
+const PlusOption rune = 2
+
+// isNumber reports whether s is a nonempty string of ASCII digits.
+func isNumber(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// Ordering describes how to deal with options that follow non-option arguments. The ordering is normally selected by a
+// prefix on the short option specification, but it can also be set explicitly with [Getopt.SetOrdering].
 //
 // The special argument '--' forces an end of option-scanning regardless of the ordering mode in effect. In the case of
 // ReturnInOrder, only '--' can cause Getopt to return -1 with Optind != len(Args).
@@ -83,22 +195,108 @@ const (
 	ReturnInOrder
 )
 
+// String returns the name of the constant for o, such as "Permute".
+func (o Ordering) String() string {
+	switch o {
+	case RequireOrder:
+		return "RequireOrder"
+	case Permute:
+		return "Permute"
+	case ReturnInOrder:
+		return "ReturnInOrder"
+	default:
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+}
+
 // Getopt is an option parser.
+//
+// A Getopt is not safe for concurrent use while scanning: [Getopt.Getopt] and its variants, the iterators, and the
+// methods that change settings must be called from one goroutine at a time. Once the parse loop has finished, the
+// methods that only report results never modify the parser, so any number of goroutines may call them concurrently,
+// provided they start, or otherwise synchronize with the scanning goroutine, after the loop ends. Those methods are
+// [Getopt.Optind], [Getopt.Remaining], [Getopt.Tail], [Getopt.Operands], [Getopt.Operand], [Getopt.TerminatorIndex],
+// [Getopt.Count], [Getopt.Permuted], [Getopt.Ordering], [Getopt.ShortOptions], [Getopt.WEnabled], [Getopt.DebugState],
+// [Getopt.CheckRequired], [Getopt.CheckDependencies], and [Getopt.CheckOperands]. Reading Args is also safe then, but
+// the slices returned by Remaining and Tail share its storage, so a goroutine that modifies them must copy them first.
 type Getopt struct {
-	Args         []string // Args holds a copy of the argument list. It gets permuted during parsing.
-	shortOptions optinfo
-	longOptions  []Option
+	Args []string // Args holds a copy of the argument list. It gets permuted during parsing.
+
+	// Opterr controls whether parsing errors are printed, like the opterr variable of GNU getopt. When it's true, the
+	// text of each error, followed by a newline, is written to Out, or to [os.Stderr] if Out is nil. The error is
+	// returned either way. Opterr is false by default.
+	Opterr bool
+	Out    io.Writer
+
+	shortOptions  optinfo
+	longOptions   []Option
+	longNameIndex []longName // Every name that can match a long option; see indexLongNames.
 
 	optind int // Optind is the index into parent argv vector.
 
 	// The rest of the argument to be scanned in the option-element in which the last option character we returned was
 	// found. This allows us to pick up the scan where we left off.
 	//
-	// If this is empty, it means to resume the scan by advancing to the next argument.
-	nextChar []rune
+	// If this is empty, it means to resume the scan by advancing to the next argument. It is always a suffix of an
+	// element of Args, so advancing through it never allocates.
+	nextChar string
 
 	firstNonopt int // Index in Args of the first non-option that has been skipped.
 	lastNonopt  int // Index in Args after the last non-option that was skipped.
+
+	specOrdering Ordering // The ordering implied by the short option specification's prefix, if any.
+	permuted     bool     // Whether exchange has moved any arguments during this parse.
+
+	permuteObserver func(from, to int) // Receives each move made by exchange; see SetPermuteObserver.
+
+	terminator   int  // Index in the original Args of the '--' that ended scanning, or -1.
+	tailStart    int  // Index in Args of the first argument after the '--' that ended scanning, or -1.
+	preserveTail bool // Whether to exclude arguments after '--' from Remaining.
+
+	operandsSorted bool // Whether to sort the non-option arguments left in Args when scanning ends.
+
+	customTerminator bool   // Whether terminatorArg replaces the doubled introducers as the terminator.
+	terminatorArg    string // The argument that ends scanning when customTerminator is set, or "" for none.
+
+	counts    map[rune]int // Number of times each option character has been returned during this parse.
+	seenLong  map[int]bool // Indices of the long options that have been matched during this parse.
+	required  []rune       // Short options that must be given; see Require.
+	singleton []rune       // Options that may be given at most once; see SetSingleton.
+	inOrder   []string     // Non-option arguments returned in ReturnInOrder mode during this parse.
+
+	dependencies []dependency // Options that require other options; see AddDependency.
+	minOperands  int          // Fewest non-option arguments allowed; see SetMinOperands.
+	maxOperands  int          // Most non-option arguments allowed, or -1 for no limit; see SetMaxOperands.
+
+	accumulators map[rune]*[]string // Destinations for the arguments of repeatable options, keyed by option character.
+
+	transforms map[rune]func(string) string // Functions that rewrite option arguments; see SetTransform.
+
+	stopAtNonoption bool // Whether to use RequireOrder regardless of the configured ordering.
+
+	introducers    []rune // Characters that introduce options. If empty, only '-' does.
+	introducer     string // The character that introduced the option element currently being scanned.
+	plusIntroducer rune   // The character that introduces a PlusOption, or 0 if there is none.
+	inOrderRune    rune   // The value of Opt.C for non-options returned in ReturnInOrder mode.
+	autoHelp       string // Text printed for an automatic --help, or empty if there is none.
+	autoVersion    string // Text printed for an automatic --version, or empty if there is none.
+	current        int    // Index in the original Args of the option element currently being scanned.
+
+	collapseRepeats bool // Whether repeated flags within one argument are returned as a single Opt.
+
+	strictRequiredArg bool // Whether a required argument may not look like an option; see SetStrictRequiredArg.
+
+	noAbbrev bool // Whether long options must be given by their full names; see SetAllowAbbrev.
+
+	longSeparator rune // The character between a long option's name and its attached argument; see SetLongSeparator.
+
+	rejectLong bool // Whether long option syntax is an error rather than a cluster of short options; see NewPosix.
+
+	unknownHandler func(string) error // Receives unrecognized options in place of errors; see SetUnknownHandler.
+
+	warnings io.Writer // Destination for warnings about deprecated options, or nil to discard them.
+
+	valFromName bool // Whether long options with a nil Flag and zero Val report the first rune of their names.
 }
 
 // Opt is a result from parsing one option off a given argument list.
@@ -107,15 +305,49 @@ type Getopt struct {
 // from Val. Opt.Arg holds the argument for that option, if any, and LongInd holds the index of the long option that
 // matched.
 //
-// If C is 1, then ordering is [ReturnInOrder] and Arg points to the current non-option argument.
+// If C is 1, or the character set with [Getopt.SetInOrderRune], then ordering is [ReturnInOrder] and Arg points to the
+// current non-option argument.
 //
 // Otherwise, C holds the rune value of the matched short option or Val of the matched long option. When a short option
 // is matched, LongInd will be -1. When a long option is matched, LongInd holds the zero-based index of the matched
-// option from the longopts argument to [NewLong].
+// option from the longopts argument to [NewLong], and Long points at that element of the longopts slice. Long is nil
+// when a short option is matched.
+//
+// ArgGiven is true when Arg came from the command line. It is false when no argument was given, as for "--color" or
+// "-c" with an optional argument, and also when Arg was supplied by the parser, as with the "true" or "false" of a
+// [Option.Negatable] option. Thus "--color=" yields ArgGiven true and an empty Arg, while "--color" yields ArgGiven
+// false. An option that wasn't given at all produces no Opt.
+//
+// Attached is true when Arg came from the same element of Args as the option itself, as in "-ofile" or "--out=file",
+// and false when it came from the following element, as in "-o file" or "--out file", or when there is no argument.
+//
+// Repeat is the number of consecutive occurrences of the option that were consumed to produce this result. It is
+// always 1 unless [Getopt.SetCollapseRepeats] is enabled.
+//
+// Args holds the arguments collected by an [Option.Greedy] option, or all the arguments of an option whose
+// [Option.NArgs] is greater than 1. It is nil for other options.
 type Opt struct {
-	C       rune
-	Arg     *string
-	LongInd int
+	C        rune
+	Arg      *string
+	ArgGiven bool
+	LongInd  int
+	Long     *Option
+	Attached bool
+	Repeat   int
+	Args     []string
+}
+
+// Equal reports whether o and other describe the same option with the same argument: their C and LongInd fields are
+// equal, and either both Arg fields are nil or the strings they point to are equal. Other fields aren't compared. Test
+// helpers that use Equal methods, such as go-cmp and Gomega's BeComparableTo, use it to compare Opt values.
+func (o Opt) Equal(other Opt) bool {
+	if o.C != other.C || o.LongInd != other.LongInd {
+		return false
+	}
+	if o.Arg == nil || other.Arg == nil {
+		return o.Arg == other.Arg
+	}
+	return *o.Arg == *other.Arg
 }
 
 // Optind returns the argument index of the next argument to be scanned. When the returned [Opt] pointer is nil, Optind
@@ -124,6 +356,113 @@ func (g *Getopt) Optind() int {
 	return g.optind
 }
 
+// TerminatorIndex returns the index of the '--' argument that ended option scanning, or -1 if scanning hasn't
+// encountered one. The index refers to the argument list as originally given, before any permutation, so arguments
+// after it in the original list can be recovered even though Args may have been rearranged since.
+func (g *Getopt) TerminatorIndex() int {
+	return g.terminator
+}
+
+// Permuted reports whether scanning has moved any arguments during the current parse, which happens in [Permute] mode
+// when options follow non-option arguments. A '--' terminator after non-option arguments counts as well, since it gets
+// moved ahead of them, so "prog file -- -x" is permuted even though no option follows an operand. Programs that want
+// users to put options first can check it after the parse loop and warn about arguments given out of order.
+func (g *Getopt) Permuted() bool {
+	return g.permuted
+}
+
+// Count returns how many times an option with character c has been returned so far during the current parse. This is
+// convenient for flags such as -v that may be repeated to increase an effect, as in "-vvv" or "-v -v". Occurrences are
+// tallied by [Opt.C], so a long option whose Val is c counts as well.
+func (g *Getopt) Count(c rune) int {
+	return g.counts[c]
+}
+
+// Remaining returns the elements of Args from the current scan position onward. Once [Getopt.Getopt] has returned a nil
+// [Opt], these are the non-option arguments, which is equivalent to Args[Optind():]. The result is never nil; it's
+// empty when every argument was consumed as an option.
+//
+// If called before scanning has finished, the result holds the arguments that haven't been scanned yet, which may still
+// include options. Non-option arguments that have been skipped but not yet permuted to the end are not included.
+//
+// When [Getopt.SetPreserveAfterTerminator] is enabled, the arguments after a '--' terminator are excluded; use
+// [Getopt.Tail] to get them.
+func (g *Getopt) Remaining() []string {
+	return g.remainingFrom(g.optind)
+}
+
+// remainingFrom returns the elements of Args from index start onward, excluding the preserved tail, if any.
+func (g *Getopt) remainingFrom(start int) []string {
+	end := len(g.Args)
+	if g.preserveTail && g.tailStart >= 0 {
+		end = g.tailStart
+	}
+	start = min(max(start, 0), end)
+	if start == end {
+		return []string{}
+	}
+	return g.Args[start:end]
+}
+
+// Tail returns the arguments that followed the '--' terminator, exactly as they appeared on the command line. The
+// result is never nil; it's empty if no terminator has been encountered or nothing followed it.
+func (g *Getopt) Tail() []string {
+	if g.tailStart < 0 || g.tailStart >= len(g.Args) {
+		return []string{}
+	}
+	return g.Args[g.tailStart:]
+}
+
+// Operands returns all the non-option arguments in the order they appeared on the command line. That includes those
+// already returned as options in [ReturnInOrder] mode, the ones that [Getopt.Remaining] returns, and those after
+// a '--' terminator even when [Getopt.SetPreserveAfterTerminator] keeps them out of Remaining. Call it after the parse
+// loop is finished. The result is never nil.
+//
+// Permutation moves skipped non-options as a block, so it never changes their relative order; Operands gives the same
+// order regardless of the ordering mode.
+func (g *Getopt) Operands() []string {
+	result := slices.Concat(g.inOrder, g.Remaining())
+	if g.preserveTail {
+		result = append(result, g.Tail()...)
+	}
+	if result == nil {
+		return []string{}
+	}
+	return result
+}
+
+// Operand returns the non-option argument at index i of the list that [Getopt.Operands] returns, counting from 0, and
+// whether there is one. Call it after the parse loop is finished. Together with [Getopt.SetMinOperands], it gives
+// simple access to positional arguments without slicing Args.
+func (g *Getopt) Operand(i int) (string, bool) {
+	operands := g.Operands()
+	if i < 0 || i >= len(operands) {
+		return "", false
+	}
+	return operands[i], true
+}
+
+// SetTerminator sets the argument that ends option scanning in place of "--", for languages in which "--" is a
+// meaningful operand. The terminator may be any argument, such as "---" or "end"; whatever it is, it's treated the
+// way "--" otherwise is, so it's skipped and everything after it is a non-option argument. Passing an empty string
+// disables the terminator, so that nothing ends scanning early. Either way, "--", and any other doubled introducer set
+// with [Getopt.SetPrefixes], becomes an ordinary non-option argument.
+func (g *Getopt) SetTerminator(s string) {
+	g.customTerminator = true
+	g.terminatorArg = s
+}
+
+// SetPreserveAfterTerminator controls whether the arguments after a '--' terminator are kept separate from the other
+// non-option arguments. By default, they are treated like any other non-options: they follow the skipped non-options in
+// the operand region at the end of Args, and [Getopt.Remaining] includes them. When preserve is true, Remaining
+// excludes them, so they are available only from [Getopt.Tail]. Programs that forward trailing arguments to another
+// program can use this to keep those arguments separate from their own operands.
+//
+// Either way, the arguments after the terminator are never reordered, and [Getopt.Optind] is unaffected.
+func (g *Getopt) SetPreserveAfterTerminator(preserve bool) {
+	g.preserveTail = preserve
+}
+
 // Getopt scans elements of Args for option characters.
 //
 // If an element of Args starts with '-', and is not exactly "-" or "--", then it is an option element. The characters
@@ -137,8 +476,8 @@ func (g *Getopt) Optind() int {
 // argument that is not an option. (The arguments have been permuted so that those that are not options now come last.)
 //
 // If an option character is seen that was not listed in the opt string when calling [New] or [NewLong], then Getopt
-// returns an [UnrecognizedOptionError]. It does not print messages to stderr; in that respect, it behaves as if the
-// Posix value opterr is always false.
+// returns an [UnrecognizedOptionError]. The error's message is also printed to [Getopt.Out], or to [os.Stderr], but
+// only when [Getopt.Opterr] is set; it plays the part of the Posix value opterr, but it's false by default.
 //
 // If an option wants an argument, then the subsequent text in the same Args element, or the text of the next Args
 // element, is returned in Opt.Arg. If the option's argument is optional, then if there is text in the current Args
@@ -146,11 +485,39 @@ func (g *Getopt) Optind() int {
 //
 // Long-named options begin with '--' instead of '-'. Their names may be abbreviated as long as the abbreviation is
 // unique or is an exact match for some defined option. If they have an argument, it follows the option name in the same
-// Args element, separated from the option name by a '=', or else in next Args element. When Getopt finds a long-named
-// option, it returns an Opt whose C field is 0 if that option's 'Flag' field is non-nil, or the value of the option's
-// 'Val' field if the 'Flag' field is nil.
+// Args element, separated from the option name by a '=' (or the character set with [Getopt.SetLongSeparator]), or else
+// in next Args element. When Getopt finds a long-named option, it returns an Opt whose C field is 0 if that option's
+// 'Flag' field is non-nil, or the value of the option's 'Val' field if the 'Flag' field is nil.
 func (g *Getopt) Getopt() (*Opt, error) {
-	return g.getoptInternal(false)
+	return g.scan(false)
+}
+
+// Next is an alternative to [Getopt.Getopt] that returns the option by value and reports the end of the options
+// explicitly. It returns the next option and true, or a zero Opt, true, and an error when the next argument is in
+// error; scanning can continue after an error. When there are no more options, it returns a zero Opt, false, and nil. A
+// loop over the options is thus:
+//
+//	for opt, ok, err := g.Next(); ok; opt, ok, err = g.Next() {
+//		...
+//	}
+func (g *Getopt) Next() (Opt, bool, error) {
+	var opt Opt
+	more, err := g.NextInto(&opt)
+	return opt, more, err
+}
+
+// NextInto is like [Getopt.Next], but it stores the option in dst, which the caller can reuse from one call to the
+// next. Neither it nor Next allocates an Opt, so they suit programs that parse many argument lists. It returns true
+// when it stores an option in dst or returns an error, and false when there are no more options. Unless it stores an
+// option, dst is set to the zero Opt.
+//
+//	var opt getopt.Opt
+//	for more, err := g.NextInto(&opt); more; more, err = g.NextInto(&opt) {
+//		...
+//	}
+func (g *Getopt) NextInto(dst *Opt) (bool, error) {
+	found, err := g.scanInto(false, dst)
+	return found || err != nil, err
 }
 
 // GetoptLong is identical to [Getopt.Getopt].
@@ -161,7 +528,7 @@ func (g *Getopt) GetoptLong() (*Opt, error) {
 // GetoptLongOnly is identical to [Getopt.Getopt] and [Getopt.GetoptLong], except that '-' as well as '--' can introduce
 // long-named options.
 func (g *Getopt) GetoptLongOnly() (*Opt, error) {
-	return g.getoptInternal(true)
+	return g.scan(true)
 }
 
 // New creates a new [Getopt] using the argument list and short option specification passed in here. Unlike the Posix
@@ -179,22 +546,128 @@ func (g *Getopt) GetoptLongOnly() (*Opt, error) {
 // GNU extension. If opts begins with '+', then arguments will not be permuted during parsing; parsing will stop when a
 // non-option argument is encountered.
 //
+// If opts includes '#', then an argument made of '-' and digits, such as "-10", is returned as a single option with C
+// set to [NumberOption] and the digits in Opt.Arg.
+//
 // The argument list is assumed to include the program name at index 0; it is not returned or processed as a real
 // argument.
 func New(args []string, opts string) *Getopt {
+	shortOptions, _ := parseShortOptionSpec(opts)
+	return newParser(args, shortOptions)
+}
+
+// newParser returns a parser for args with the given short options and default settings for everything else.
+func newParser(args []string, shortOptions optinfo) *Getopt {
 	g := Getopt{
-		Args:         args,
-		shortOptions: parseShortOptionSpec(opts),
+		shortOptions: shortOptions,
+		longOptions:  nil,
+		specOrdering: shortOptions.Ordering,
+		maxOperands:  -1,
 
-		longOptions: nil,
-		optind:      1,
-		nextChar:    nil,
-		firstNonopt: 1,
-		lastNonopt:  1,
+		longSeparator: '=',
+		inOrderRune:   InOrderOption,
 	}
+	g.SetArgs(args)
 	return &g
 }
 
+// NewFromArgs is like [New], but for an argument list that doesn't begin with the program name, such as one received
+// by a library function. The parser's Args is args with progName inserted at index 0, so indices reported by the
+// parser, such as [Getopt.Optind], are one greater than the corresponding indices in args. The args slice itself is
+// never modified.
+func NewFromArgs(progName string, args []string, opts string) *Getopt {
+	return New(slices.Concat([]string{progName}, args), opts)
+}
+
+// SetArgs installs a new argument list and starts a fresh scan over it, keeping the option definitions and other
+// settings of the parser. This avoids re-parsing the option specification when the same options apply to many
+// argument lists. As with [New], args is assumed to include the program name at index 0.
+//
+// Everything the parser has recorded about the previous scan is discarded, including the tallies reported by
+// [Getopt.Count] and the options considered by [Getopt.CheckRequired]. Slices registered to accumulate arguments are
+// not cleared, so they continue to collect arguments from the new list.
+func (g *Getopt) SetArgs(args []string) {
+	g.Args = args
+	g.optind = 1
+	g.nextChar = ""
+	g.firstNonopt = 1
+	g.lastNonopt = 1
+	g.permuted = false
+	g.counts = map[rune]int{}
+	g.seenLong = map[int]bool{}
+	g.inOrder = nil
+	g.terminator = -1
+	g.tailStart = -1
+	g.introducer = dash
+}
+
+// Rewind starts a fresh scan over the same Args, as for [Getopt.SetArgs], keeping the option definitions and other
+// settings. This suits a parse in two passes, such as one that first looks for an option that affects how the others
+// are handled. Everything recorded about the previous scan is discarded, including the tallies reported by
+// [Getopt.Count].
+//
+// Args is not restored to its original order. If the previous scan permuted it, the new scan sees the permuted order,
+// in which the options it had reached come before the non-option arguments that were skipped. Positions reported
+// afterward, such as [PositionedError.Index], are positions in that order.
+func (g *Getopt) Rewind() {
+	g.SetArgs(g.Args)
+}
+
+// Rebind replaces the parser's option definitions with those given by opts and longOptions, as for [NewLong], and
+// continues the scan from the current position in Args with the new definitions. This suits commands with subcommands:
+// parse the global options with [RequireOrder], read the subcommand name from [Getopt.Remaining], and then Rebind with
+// the subcommand's options. The subcommand name stays where it is; with the default [Permute] ordering, it gets skipped
+// as a non-option argument and ends up at the start of Remaining.
+//
+// Any unscanned characters of the current option element are discarded. The ordering comes from the prefix of opts, and
+// [Getopt.StopAtFirstNonOption] is turned off. Everything tied to the old definitions is discarded as well: the tallies
+// reported by [Getopt.Count], the options registered with [Getopt.Require], [Getopt.AddDependency],
+// [Getopt.Accumulate], [Getopt.SetTransform], and [Getopt.SetSingleton], and the record of which long options were
+// given. Other settings are kept.
+func (g *Getopt) Rebind(opts string, longOptions []Option) {
+	g.shortOptions, _ = parseShortOptionSpec(opts)
+	g.specOrdering = g.shortOptions.Ordering
+	g.setLongOptions(longOptions)
+	if len(g.nextChar) > 0 {
+		// Scanning stopped partway through an option element, so optind hasn't moved past it yet.
+		g.optind++
+		g.nextChar = ""
+	}
+	g.stopAtNonoption = false
+	g.counts = map[rune]int{}
+	g.seenLong = map[int]bool{}
+	g.required = nil
+	g.singleton = nil
+	g.dependencies = nil
+	g.accumulators = nil
+	g.transforms = nil
+}
+
+// NewStrict is like [New], but it validates the option specification instead of silently accepting questionable
+// definitions. It returns a [SpecSyntaxError] if opts is malformed, as with "a:::" or a ';' that doesn't follow 'W',
+// and a [DuplicateOptionError] if any option letter appears more than once in opts. Only the first problem is
+// reported.
+func NewStrict(args []string, opts string) (*Getopt, error) {
+	if _, err := parseShortOptionSpec(opts); err != nil {
+		return nil, err
+	}
+	return New(args, opts), nil
+}
+
+// NewPosix creates a new Getopt that accepts only what POSIX getopt accepts, for checking that a program behaves the
+// same under a strict getopt(3). Scanning uses [RequireOrder], so it stops at the first non-option argument, even if
+// opts begins with '-'. There are no long options, so an argument such as "--name" or "--name=value" is reported as an
+// [UnrecognizedOptionError] for the whole name rather than scanned as short options. The -W extension is disabled, so
+// 'W' in opts is an ordinary option letter that takes no argument.
+func NewPosix(args []string, opts string) *Getopt {
+	g := New(args, opts)
+	_ = g.SetOrdering(RequireOrder) // The explicit ordering wins over a conflicting prefix.
+	g.EnableWExtension(false)
+	g.noAbbrev = true
+	g.rejectLong = true
+	return g
+}
+
 // NewLong creates a new Getopt using the argument list and short and long option specifications given. See [Getopt].
 //
 // If opts includes 'W' followed by ';', then a GNU extension is enabled that allows long options to be specified as
@@ -202,10 +675,347 @@ func New(args []string, opts string) *Getopt {
 // '--foo=bar'.
 func NewLong(args []string, opts string, longOptions []Option) *Getopt {
 	g := New(args, opts)
-	g.longOptions = longOptions
+	g.setLongOptions(longOptions)
 	return g
 }
 
+// SetOrdering selects how options that follow non-option arguments are handled, overriding whatever ordering was
+// implied by the short option specification. The explicit ordering always wins; it takes effect even when an error is
+// returned.
+//
+// If the specification began with '+' or '-' and the requested ordering differs from the one that prefix selected,
+// SetOrdering returns an [OrderingConflictError] so the disagreement doesn't go unnoticed. A specification without a
+// prefix never conflicts.
+func (g *Getopt) SetOrdering(ordering Ordering) error {
+	g.shortOptions.Ordering = ordering
+	if g.specOrdering != Permute && g.specOrdering != ordering {
+		return OrderingConflictError{
+			Requested: ordering,
+			Spec:      g.specOrdering,
+		}
+	}
+	return nil
+}
+
+// SetInOrderRune sets the value of [Opt.C] for non-option arguments returned in [ReturnInOrder] mode, which is
+// [InOrderOption] by default. The value must not be mistaken for an option, so SetInOrderRune returns an
+// [InOrderRuneError] and leaves the setting unchanged if r is a short option character, the Val of a long option
+// whose Flag is nil, 0 (which reports long options that set a flag), [PlusOption], or [NumberOption]. Options defined
+// later, such as with [Getopt.Rebind], are not checked.
+func (g *Getopt) SetInOrderRune(r rune) error {
+	conflict := r == 0 || r == PlusOption || r == NumberOption || g.shortOptions.HasOpt(r) ||
+		slices.ContainsFunc(g.longOptions, func(o Option) bool {
+			return o.Flag == nil && o.Val == r
+		})
+	if conflict {
+		return InOrderRuneError{Rune: r}
+	}
+	g.inOrderRune = r
+	return nil
+}
+
+// StopAtFirstNonOption switches scanning to [RequireOrder] when stop is true, so that the next non-option argument ends
+// option scanning, regardless of the ordering requested by the option specification or [Getopt.SetOrdering]. Passing
+// false restores the configured ordering. It may be called at any point during the scan, such as after seeing an
+// option that means the rest of the command line belongs to a subcommand.
+//
+// If the scan had been permuting arguments, non-options that were skipped before the switch are not lost. They are
+// moved ahead of the unscanned arguments when scanning stops, so [Getopt.Optind] and [Getopt.Remaining] begin with
+// them, in their original order.
+func (g *Getopt) StopAtFirstNonOption(stop bool) {
+	g.stopAtNonoption = stop
+}
+
+// Ordering returns the ordering in effect for the scan. It reflects the prefix of the short option specification,
+// [Getopt.SetOrdering], and [Getopt.StopAtFirstNonOption].
+func (g *Getopt) Ordering() Ordering {
+	if g.stopAtNonoption {
+		return RequireOrder
+	}
+	return g.shortOptions.Ordering
+}
+
+// ShortOptions returns the short options defined by the parser's short option specification, with the argument
+// disposition of each. With the -W extension enabled by "W;", 'W' is reported as requiring an argument. The map is a
+// copy, so changing it doesn't affect the parser.
+func (g *Getopt) ShortOptions() map[rune]ArgumentDisposition {
+	result := make(map[rune]ArgumentDisposition, len(g.shortOptions.Opts))
+	for c := range g.shortOptions.Opts {
+		result[c] = shortDisposition(g.shortOptions, c)
+	}
+	return result
+}
+
+// SetPlusIntroducer makes arguments that begin with c, typically '+', into options of their own, as with the format
+// argument of "date +%Y". Each such argument is returned as an [Opt] with C set to [PlusOption] and Arg holding the
+// rest of the argument, which is not examined further. Like other options, these arguments are found wherever they
+// appear when permuting. A lone c is still a non-option argument. This is unrelated to a '+' at the start of the short
+// option specification, which selects [RequireOrder]. If c is also set with [Getopt.SetPrefixes], this takes
+// precedence. Passing 0 turns the feature off, which is the default.
+func (g *Getopt) SetPlusIntroducer(c rune) {
+	g.plusIntroducer = c
+}
+
+// EnableWExtension turns the -W extension on or off, regardless of whether the short option specification included
+// "W;". When it's on, "-W foo" is equivalent to "--foo", provided the parser has long options; enabling it defines -W
+// if the specification didn't. When it's off, 'W' in the specification is an ordinary option that takes no argument.
+func (g *Getopt) EnableWExtension(enable bool) {
+	g.shortOptions.W = enable
+	if enable && !g.shortOptions.HasOpt('W') {
+		// The map may belong to a Spec shared with other parsers, so don't modify it in place.
+		g.shortOptions.Opts = maps.Clone(g.shortOptions.Opts)
+		g.shortOptions.Opts['W'] = NoArgument
+	}
+}
+
+// WEnabled reports whether the -W extension is on, either because the short option specification included "W;" or
+// because of [Getopt.EnableWExtension].
+func (g *Getopt) WEnabled() bool {
+	return g.shortOptions.W
+}
+
+// SetPrefixes sets the characters that introduce options. By default, only '-' does. For example, calling
+// SetPrefixes('-', '/') makes "/x" equivalent to "-x". Whatever character introduces a short option, doubling it
+// introduces a long option, and the doubled character alone ends option scanning the same way "--" does, so "//foo" is
+// the same as "--foo" and "//" is another argument terminator. Calling SetPrefixes with no arguments restores the
+// default.
+//
+// Error values report the introducer that was actually used in their Prefix fields.
+func (g *Getopt) SetPrefixes(introducers ...rune) {
+	g.introducers = slices.Clone(introducers)
+}
+
+// Accumulate registers dst to receive the argument of each occurrence of an option whose character is c, similar to
+// the Accumulate field of [Option]. It applies to short options as well as long options whose Val is c, unless the
+// option has an Accumulate destination of its own. Options still get returned from [Getopt.Getopt] as usual. Passing
+// a nil dst removes the registration.
+func (g *Getopt) Accumulate(c rune, dst *[]string) {
+	if dst == nil {
+		delete(g.accumulators, c)
+		return
+	}
+	if g.accumulators == nil {
+		g.accumulators = map[rune]*[]string{}
+	}
+	g.accumulators[c] = dst
+}
+
+// SetTransform registers fn to rewrite the argument of each occurrence of an option whose character is c before the
+// argument is stored in [Opt.Arg], similar to the Transform field of [Option]. It applies to short options as well as
+// long options whose Val is c, unless the option has a Transform function of its own. Passing a nil fn removes the
+// registration.
+func (g *Getopt) SetTransform(c rune, fn func(string) string) {
+	if fn == nil {
+		delete(g.transforms, c)
+		return
+	}
+	if g.transforms == nil {
+		g.transforms = map[rune]func(string) string{}
+	}
+	g.transforms[c] = fn
+}
+
+// SetCollapseRepeats controls whether a short option that takes no argument and is repeated consecutively within a
+// single element of Args is returned once instead of once per occurrence. When enabled, "-vvv" yields a single [Opt]
+// with C set to 'v' and Repeat set to 3. Options that take arguments are never collapsed, and repetitions in separate
+// elements, such as "-v -v", are still returned separately. Collapsing is disabled by default.
+func (g *Getopt) SetCollapseRepeats(enable bool) {
+	g.collapseRepeats = enable
+}
+
+// SetOperandsSorted controls whether the non-option arguments left in Args are sorted when scanning ends, for programs
+// that process their operands in sorted order. When enabled, the arguments that [Getopt.Remaining] returns are sorted
+// in place, lexicographically by bytes, as soon as [Getopt.Getopt] reports that there are no more options. Options are
+// never moved. The arguments after a '--' terminator are sorted along with the others unless
+// [Getopt.SetPreserveAfterTerminator] keeps them separate, in which case they stay as they were given. Non-options
+// already returned in [ReturnInOrder] mode aren't affected, and with [RequireOrder], everything after the first
+// non-option is sorted. Each move made by sorting is reported to the function set with [Getopt.SetPermuteObserver], but
+// it doesn't count for [Getopt.Permuted]. Sorting is disabled by default.
+func (g *Getopt) SetOperandsSorted(sorted bool) {
+	g.operandsSorted = sorted
+}
+
+// finishScan does the work due when scanning ends, which is sorting the remaining arguments if requested.
+func (g *Getopt) finishScan() {
+	if !g.operandsSorted {
+		return
+	}
+	operands := g.remainingFrom(g.optind)
+	if g.permuteObserver == nil {
+		slices.Sort(operands)
+		return
+	}
+	// Sort by insertion so that each move can be reported.
+	start := g.optind
+	for i := 1; i < len(operands); i++ {
+		arg := operands[i]
+		j := i
+		for j > 0 && operands[j-1] > arg {
+			j--
+		}
+		if j != i {
+			copy(operands[j+1:i+1], operands[j:i])
+			operands[j] = arg
+			g.permuteObserver(start+i, start+j)
+		}
+	}
+}
+
+// SetPermuteObserver registers fn to be told how permutation moves arguments, such as to map positions in Args back to
+// positions on the original command line. Indices are into the live Args slice. Each call means that the element at
+// index from was moved to index to, which is lower, and the elements from to up to from were each shifted up by one to
+// make room, as if by [slices.Delete] followed by [slices.Insert]. Applying each move to a slice holding the original
+// index of each element, as it's reported, keeps that slice in step with Args. Passing nil removes the observer, which
+// is the default.
+func (g *Getopt) SetPermuteObserver(fn func(from, to int)) {
+	g.permuteObserver = fn
+}
+
+// SetLongSeparator sets the character that separates a long option's name from an argument attached to it, which is
+// '=' by default. For example, after SetLongSeparator(':'), "--name:value" gives the option "name" the argument
+// "value", and "--name=value" is taken as an option called "name=value", which is normally unrecognized. An '=' after
+// the separator is part of the argument. The separator also applies to long options given with the -W extension and
+// with [Getopt.GetoptLongOnly].
+func (g *Getopt) SetLongSeparator(sep rune) {
+	g.longSeparator = sep
+}
+
+// SetAllowAbbrev controls whether long options may be abbreviated. By default, as in GNU getopt, any unambiguous
+// prefix of a long option's name selects it, so "--verb" means "--verbose". Such abbreviations can change meaning when
+// options are added later. When abbreviations are disallowed, long options must be given by their full names, anything
+// else is reported as an [UnrecognizedOptionError], and an [AmbiguousOptionError] never occurs.
+func (g *Getopt) SetAllowAbbrev(allow bool) {
+	g.noAbbrev = !allow
+}
+
+// SetStrictRequiredArg controls whether an option that requires an argument may take the following element as its
+// argument when that element looks like an option. By default, as in GNU getopt, "-o -x" gives -o the argument "-x".
+// When strict is true, that is reported as an [ArgumentRequiredError], and "-x" is scanned next as an option. A lone
+// "-" is still accepted as an argument, and arguments attached to the option, as in "-o-x" or "--output=-x", are never
+// affected.
+func (g *Getopt) SetStrictRequiredArg(strict bool) {
+	g.strictRequiredArg = strict
+}
+
+// SetUnknownHandler installs a function that receives unrecognized options instead of having them reported as an
+// [UnrecognizedOptionError]. This suits programs that pass unknown options along to another program. The function is
+// called with the option as it appeared, including its prefix: "-x" for an unknown letter, even within a cluster such
+// as "-axb", and the whole element, such as "--name=value", for an unknown long option. If the function returns nil,
+// scanning continues with the next option; otherwise, [Getopt.Getopt] returns that error. Since the parser doesn't
+// know whether an unknown option takes an argument, a separate argument is scanned as usual. Passing nil restores the
+// default behavior.
+func (g *Getopt) SetUnknownHandler(fn func(opt string) error) {
+	g.unknownHandler = fn
+}
+
+// SetWarnWriter sets the writer that receives warnings, such as those about options marked [Option.Deprecated]. Each
+// warning is written as a single line. Warnings are discarded by default, and passing nil discards them again.
+func (g *Getopt) SetWarnWriter(w io.Writer) {
+	g.warnings = w
+}
+
+// SetAutoHelp makes the parser handle "--help" itself, unless a long option already uses the name "help". When
+// "--help", or an unambiguous abbreviation of it, is given, usage is written to Out, or to [os.Stdout] if Out is nil,
+// followed by a newline if it doesn't already end with one, and [Getopt.Getopt] returns an error matching
+// [ErrHelpRequested], which isn't printed even if Opterr is set. The program should then exit with status 0. The option
+// takes no argument. An abbreviation that also matches a defined option, such as "--he" alongside "--hex", is
+// ambiguous, as usual. Passing an empty string turns automatic help off, which is the default. [HandleError] returns 0
+// for the sentinel without printing anything, so a program that passes its parse error to HandleError exits
+// successfully.
+func (g *Getopt) SetAutoHelp(usage string) {
+	g.autoHelp = usage
+	g.indexLongNames()
+}
+
+// SetAutoVersion is like [Getopt.SetAutoHelp], but for "--version". It writes version and returns an error matching
+// [ErrVersionRequested].
+func (g *Getopt) SetAutoVersion(version string) {
+	g.autoVersion = version
+	g.indexLongNames()
+}
+
+// hasLongOptions reports whether the parser recognizes any long options, including those handled automatically.
+func (g *Getopt) hasLongOptions() bool {
+	return len(g.longOptions) > 0 || g.autoHelp != "" || g.autoVersion != ""
+}
+
+// setLongOptions installs longOptions as the parser's long option definitions and indexes their names.
+func (g *Getopt) setLongOptions(longOptions []Option) {
+	g.longOptions = longOptions
+	g.indexLongNames()
+}
+
+// indexLongNames rebuilds the list of every name that can match one of the parser's long options, as for the function
+// longNames, followed by the names of the automatic options that are enabled and not already defined. The index of an
+// automatic option is beyond the end of longOptions. It must be called whenever the long options or the automatic
+// options change, so that scanning doesn't have to rebuild the list for each option.
+func (g *Getopt) indexLongNames() {
+	names := longNames(g.longOptions)
+	autos := [...]struct{ name, text string }{
+		{autoHelpName, g.autoHelp},
+		{autoVersionName, g.autoVersion},
+	}
+	for i, auto := range autos {
+		defined := slices.ContainsFunc(names, func(n longName) bool {
+			return n.name == auto.name
+		})
+		if auto.text != "" && !defined {
+			names = append(names, longName{name: auto.name, index: len(g.longOptions) + i})
+		}
+	}
+	g.longNameIndex = names
+}
+
+// Names of the options handled by SetAutoHelp and SetAutoVersion.
+const (
+	autoHelpName    = "help"
+	autoVersionName = "version"
+)
+
+// autoOption handles an automatic option, writing its text and returning its sentinel error. Nameend is the part of
+// the element after the option name, which must be empty since the automatic options take no argument.
+func (g *Getopt) autoOption(name, nameend, prefix string) error {
+	if nameend != "" {
+		return ArgumentNotAllowedError{
+			Option: name,
+			Prefix: prefix,
+		}
+	}
+	text, err := g.autoHelp, ErrHelpRequested
+	if name == autoVersionName {
+		text, err = g.autoVersion, ErrVersionRequested
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	out := g.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	_, _ = io.WriteString(out, text)
+	return err
+}
+
+// SetValFromName controls what is returned in [Opt.C] for a long option whose Flag is nil and whose Val is zero. By
+// default, C is 0 and the option can only be identified by [Opt.LongInd]. When enabled, C is the first rune of the
+// option's Name instead, so a table of long-only options can be dispatched on C alone. The rune also counts as the
+// option's character for [Getopt.Count], [Getopt.Require], and the like.
+//
+// Take care that no two such options share a first letter, and that none shares its first letter with a short option
+// or another long option's Val; otherwise they can't be told apart by C.
+func (g *Getopt) SetValFromName(enable bool) {
+	g.valFromName = enable
+}
+
+// isFlag reports whether c is a short option that takes no argument.
+func (g *Getopt) isFlag(c rune) bool {
+	if c == 'W' && g.shortOptions.W && g.hasLongOptions() {
+		return false
+	}
+	d, ok := g.shortOptions.Opts[c]
+	return ok && d == NoArgument
+}
+
 // exchange swaps two adjacent subsequences of Args. One subsequence is elements [firstNonopt,lastNonopt) which
 // contains all the non-options that have been skipped so far. The other is elements [lastNonopt,optind), which
 // contains all the options processed since those non-options were skipped.
@@ -248,127 +1058,520 @@ func (g *Getopt) exchange() {
 		}
 	}
 
+	g.permuted = true
+	if g.permuteObserver != nil {
+		// Describe the rearrangement as moving each option, in turn, ahead of all the non-options.
+		for i := range g.optind - g.lastNonopt {
+			g.permuteObserver(g.lastNonopt+i, g.firstNonopt+i)
+		}
+	}
+
 	// Update records for the slots the non-options now occupy.
 	g.firstNonopt += (g.optind - g.lastNonopt)
 	g.lastNonopt = g.optind
 }
 
-// Process the argument starting with nextChar as a long option. optind should *not* have been advanced over this
-// argument.
-//
-// If the value returned is nil, it was not actually a long option, the state is unchanged, and the argument should be
-// processed as a set of short options (this can only happen when longOnly is true). Otherwise, the option (and its
-// argument, if any) have been consumed and the return value is the value to return from getoptInternal.
-func (g *Getopt) processLongOption(longOnly bool, prefix string) (*Opt, error) {
-	namelen := slices.Index(g.nextChar, '=')
-	if namelen == -1 {
-		namelen = len(g.nextChar)
+// longName is one name by which a long option can be given on the command line.
+type longName struct {
+	name    string
+	index   int  // Index of the option in longOptions.
+	negated bool // Whether name is the "no-" form of a negatable option.
+	alias   bool // Whether name is one of the option's Aliases, which only match exactly.
+}
+
+// longNames lists every name that can match one of the given long options, in the order the options were defined.
+// Each option contributes its own name followed by its aliases, and each negatable option contributes the "no-" form of
+// each of those names immediately after it.
+func longNames(longOptions []Option) []longName {
+	names := make([]longName, 0, len(longOptions))
+	for i, p := range longOptions {
+		for j, name := range slices.Concat([]string{p.Name}, p.Aliases) {
+			names = append(names, longName{name: name, index: i, alias: j > 0})
+			if p.Negatable {
+				names = append(names, longName{name: negationPrefix + name, index: i, negated: true, alias: j > 0})
+			}
+		}
 	}
-	nameend := g.nextChar[namelen:]
+	return names
+}
+
+// distinctNames reports whether two names refer to options that behave differently, so that an abbreviation matching
+// both is ambiguous.
+func (g *Getopt) distinctNames(a, b longName) bool {
+	if a.index >= len(g.longOptions) || b.index >= len(g.longOptions) {
+		// The automatic options are unlike any other.
+		return true
+	}
+	pa := &g.longOptions[a.index]
+	pb := &g.longOptions[b.index]
+	return a.negated != b.negated || pa.HasArg != pb.HasArg || pa.Flag != pb.Flag || pa.Val != pb.Val
+}
 
+// matchLongName finds the element of names that targetName selects, either exactly or, unless abbreviations are
+// disallowed, as an abbreviation. It returns the index of the match, or -1 if there is none. If targetName abbreviates
+// more than one name that would behave differently, it also returns those names, sorted, and the match should be
+// treated as ambiguous.
+func (g *Getopt) matchLongName(names []longName, targetName string, longOnly bool) (int, []string) {
 	// First, look for an exact match.
-	targetName := string(g.nextChar[:namelen])
-	optionIndex := slices.IndexFunc(g.longOptions, func(p Option) bool {
-		return targetName == p.Name
+	found := slices.IndexFunc(names, func(n longName) bool {
+		return targetName == n.name
 	})
-	var pfound *Option
-	if optionIndex != -1 {
-		// Exact match found.
-		pfound = &g.longOptions[optionIndex]
-	}
-
-	if pfound == nil {
-		// Didn't find an exact match, so look for abbreviations.
-		var ambig AmbiguousOptionError
-
-		for i, p := range g.longOptions {
-			if strings.HasPrefix(p.Name, string(g.nextChar[:namelen])) {
-				if pfound == nil {
-					// First nonexact match found.
-					optionIndex = i
-					pfound = &g.longOptions[optionIndex]
-					ambig.Candidates = append(ambig.Candidates, p.Name)
-				} else if longOnly || pfound.HasArg != p.HasArg || pfound.Flag != p.Flag || pfound.Val != p.Val {
-					// Second or later nonexact match found.
-					ambig.Candidates = append(ambig.Candidates, p.Name)
-				}
+	if found != -1 || g.noAbbrev {
+		return found, nil
+	}
+
+	// Didn't find an exact match, so look for abbreviations.
+	var candidates []string
+	for i, n := range names {
+		if !n.alias && strings.HasPrefix(n.name, targetName) {
+			if found == -1 {
+				// First nonexact match found.
+				found = i
+				candidates = append(candidates, n.name)
+			} else if longOnly || g.distinctNames(names[found], n) {
+				// Second or later nonexact match found.
+				candidates = append(candidates, n.name)
 			}
 		}
+	}
+	if len(candidates) > 1 {
+		slices.Sort(candidates)
+		return found, candidates
+	}
+	return found, nil
+}
 
-		if len(ambig.Candidates) > 1 {
-			ambig.Option = string(g.nextChar)
-			ambig.prefix = prefix
+// Process the argument starting with nextChar as a long option. optind should *not* have been advanced over this
+// argument.
+//
+// If it returns false and a nil error, it was not actually a long option, the state is unchanged, and the argument
+// should be processed as a set of short options (this can only happen when longOnly is true). If it returns true, the
+// option (and its argument, if any) have been consumed and stored in opt. If it returns an error, the argument has been
+// consumed and the error is the value to return from getoptInternal.
+func (g *Getopt) processLongOption(longOnly bool, prefix string, opt *Opt) (bool, error) {
+	namelen := strings.IndexRune(g.nextChar, g.longSeparator)
+	if namelen == -1 {
+		namelen = len(g.nextChar)
+	}
+	nameend := g.nextChar[namelen:]
 
-			g.nextChar = nil
-			g.optind++
-			return nil, ambig
+	names := g.longNameIndex
+	found, candidates := g.matchLongName(names, g.nextChar[:namelen], longOnly)
+	if len(candidates) > 1 {
+		ambig := AmbiguousOptionError{
+			Option:     g.nextChar,
+			Prefix:     prefix,
+			Candidates: candidates,
 		}
+		g.nextChar = ""
+		g.optind++
+		return false, ambig
 	}
 
-	if pfound == nil {
+	if found == -1 {
 		// Can't find it as a long option. If this is not GetoptLongOnly, or the option starts with '--' or is not a
 		// valid short option, then it's an error.
-		if !longOnly || g.Args[g.optind][1] == '-' || !g.shortOptions.HasOpt(g.nextChar[0]) {
+		if !longOnly || g.longPrefixLen(g.Args[g.optind]) != 0 || !g.shortOptions.HasOpt(firstRune(g.nextChar)) {
 			unrecog := UnrecognizedOptionError{
-				Option: string(g.nextChar),
-				prefix: prefix,
+				Option: g.nextChar,
+				Prefix: prefix,
 			}
-			g.nextChar = nil
+			g.nextChar = ""
 			g.optind++
-			return nil, unrecog
+			return false, unrecog
 		}
 
 		// Otherwise interpret it as a short option.
-		return nil, nil
+		return false, nil
 	}
+	match := names[found]
+	optionIndex := match.index
 
 	// We have found a matching long option. Consume it.
 	g.optind++
-	g.nextChar = nil
+	g.nextChar = ""
+	if optionIndex >= len(g.longOptions) {
+		return false, g.autoOption(match.name, nameend, prefix)
+	}
+	pfound := &g.longOptions[optionIndex]
+	if pfound.Deprecated != "" && g.warnings != nil {
+		_, _ = fmt.Fprintf(g.warnings, "warning: %s%s is deprecated: %s\n", prefix, match.name, pfound.Deprecated)
+	}
 	var arg *string
-	if len(nameend) != 0 {
+	attached := false
+	switch {
+	case len(nameend) != 0:
 		if pfound.HasArg == NoArgument {
-			return nil, ArgumentNotAllowedError{
-				Option: pfound.Name,
-				prefix: prefix,
+			return false, ArgumentNotAllowedError{
+				Option: match.name,
+				Prefix: prefix,
 			}
 		}
-		s := string(nameend[1:])
+		// "--name=" gives an empty argument. That satisfies a required argument, and for an optional argument it's
+		// distinct from giving no argument at all.
+		s := nameend[utf8.RuneLen(g.longSeparator):]
 		arg = &s
-	} else if pfound.HasArg == RequiredArgument {
-		if g.optind >= len(g.Args) {
-			return nil, ArgumentRequiredError{
-				Option: pfound.Name,
-				prefix: prefix,
+		attached = true
+	case pfound.HasArg == RequiredArgument:
+		if g.noSeparateArgument() {
+			return false, ArgumentRequiredError{
+				Option: match.name,
+				Prefix: prefix,
 			}
 		}
 		arg = &g.Args[g.optind]
 		g.optind++
+	case pfound.HasArg == OptionalArgument:
+		// An optional argument can only be attached with the separator. As with GNU getopt, the next element is never
+		// taken as the argument, so "--color auto" leaves "auto" as a non-option argument.
+	}
+	argGiven := arg != nil
+	if pfound.Negatable && arg == nil {
+		s := strconv.FormatBool(!match.negated)
+		arg = &s
 	}
 
 	if pfound.Flag != nil {
 		*pfound.Flag = pfound.Val
-		return &Opt{
-			C:       0,
-			LongInd: optionIndex,
-			Arg:     arg,
-		}, nil
+		*opt = Opt{
+			C:        0,
+			LongInd:  optionIndex,
+			Long:     pfound,
+			Arg:      arg,
+			ArgGiven: argGiven,
+			Attached: attached,
+			Repeat:   1,
+		}
+		return true, nil
+	}
+	c := pfound.Val
+	if c == 0 && g.valFromName {
+		c, _ = utf8.DecodeRuneInString(pfound.Name)
+	}
+	*opt = Opt{
+		C:        c,
+		LongInd:  optionIndex,
+		Long:     pfound,
+		Arg:      arg,
+		ArgGiven: argGiven,
+		Attached: attached,
+		Repeat:   1,
+	}
+	return true, nil
+}
+
+// noSeparateArgument reports whether the element at optind can't be taken as the argument of the option just scanned,
+// either because there are no more elements or because it looks like an option and SetStrictRequiredArg is enabled.
+func (g *Getopt) noSeparateArgument() bool {
+	return g.optind >= len(g.Args) || g.strictRequiredArg && !g.nonoption(g.Args[g.optind])
+}
+
+// isIntroducer reports whether c is one of the characters that introduce options.
+func (g *Getopt) isIntroducer(c rune) bool {
+	if len(g.introducers) == 0 {
+		return c == '-'
+	}
+	return slices.Contains(g.introducers, c)
+}
+
+// nonoption tests whether ARGV[optind] holds a non-option argument. A terminator is not a non-option argument, but a
+// doubled introducer that isn't the terminator is.
+func (g *Getopt) nonoption(s string) bool {
+	if g.isTerminator(s) {
+		return false
+	}
+	c, size := utf8.DecodeRuneInString(s)
+	return size == 0 || !g.isIntroducer(c) && !g.isPlus(c) || len(s) == size || g.isDoubledIntroducer(s)
+}
+
+// isPlus reports whether c is the introducer set with SetPlusIntroducer.
+func (g *Getopt) isPlus(c rune) bool {
+	return g.plusIntroducer != 0 && c == g.plusIntroducer
+}
+
+// isTerminator tests whether s marks the end of options. That's a doubled introducer, such as "--", unless another
+// terminator has been set with SetTerminator.
+func (g *Getopt) isTerminator(s string) bool {
+	if g.customTerminator {
+		return g.terminatorArg != "" && s == g.terminatorArg
+	}
+	return g.isDoubledIntroducer(s)
+}
+
+// isDoubledIntroducer tests whether s consists of just two copies of an introducer, such as "--".
+func (g *Getopt) isDoubledIntroducer(s string) bool {
+	c, size := utf8.DecodeRuneInString(s)
+	return size != 0 && g.isIntroducer(c) && s[size:] == string(c)
+}
+
+// longPrefixLen returns the length in bytes of the doubled introducer, such as "--", that begins s, or 0 if s does not
+// begin with one.
+func (g *Getopt) longPrefixLen(s string) int {
+	c, size := utf8.DecodeRuneInString(s)
+	if size == 0 || !g.isIntroducer(c) || !strings.HasPrefix(s[size:], string(c)) {
+		return 0
+	}
+	return 2 * size
+}
+
+// decodeRune returns the first character of s and its width in bytes. Option letters are nearly always ASCII, so that
+// case is handled by indexing the byte directly; anything else falls back to UTF-8 decoding.
+func decodeRune(s string) (rune, int) {
+	if len(s) > 0 && s[0] < utf8.RuneSelf {
+		return rune(s[0]), 1
+	}
+	return utf8.DecodeRuneInString(s)
+}
+
+// firstRune returns the first character of s.
+func firstRune(s string) rune {
+	c, _ := decodeRune(s)
+	return c
+}
+
+// isInOrder reports whether opt is a non-option argument returned in [ReturnInOrder] mode.
+func (g *Getopt) isInOrder(opt *Opt) bool {
+	return opt.C == g.inOrderRune && opt.Long == nil
+}
+
+// record updates the parser's bookkeeping for an option that is about to be returned.
+func (g *Getopt) record(opt *Opt) {
+	g.counts[opt.C] += opt.Repeat
+	if opt.Long != nil {
+		g.seenLong[opt.LongInd] = true
+	}
+	if g.isInOrder(opt) {
+		g.inOrder = append(g.inOrder, *opt.Arg)
 	}
-	return &Opt{
-		C:       pfound.Val,
-		LongInd: optionIndex,
-		Arg:     arg,
-	}, nil
+
+	if opt.Arg != nil {
+		if dst := g.accumulator(opt); dst != nil {
+			*dst = append(*dst, *opt.Arg)
+		}
+	}
+}
+
+// accumulator returns the slice that receives the arguments of opt, or nil if there is none. An option's own
+// [Option.Accumulate] takes precedence over one registered with [Getopt.Accumulate]. A short option uses the Accumulate
+// destination of a long option whose Flag is nil and whose Val is the short option's character.
+func (g *Getopt) accumulator(opt *Opt) *[]string {
+	if opt.Long != nil {
+		if opt.Long.Accumulate != nil {
+			return opt.Long.Accumulate
+		}
+		return g.accumulators[opt.C]
+	}
+	for _, o := range g.longOptions {
+		if o.Flag == nil && o.Val == opt.C && o.Accumulate != nil {
+			return o.Accumulate
+		}
+	}
+	return g.accumulators[opt.C]
+}
+
+// transform rewrites the argument of opt with the function registered for it, if any.
+func (g *Getopt) transform(opt *Opt) {
+	if !opt.ArgGiven || g.isInOrder(opt) {
+		return
+	}
+	if fn := g.transformer(opt); fn != nil {
+		arg := fn(*opt.Arg)
+		opt.Arg = &arg
+		for i := range opt.Args {
+			opt.Args[i] = fn(opt.Args[i])
+		}
+	}
+}
+
+// transformer returns the function that rewrites the arguments of opt, or nil if there is none. An option's own
+// [Option.Transform] takes precedence over one registered with [Getopt.SetTransform]. A short option uses the Transform
+// of a long option whose Flag is nil and whose Val is the short option's character.
+func (g *Getopt) transformer(opt *Opt) func(string) string {
+	if opt.Long != nil {
+		if opt.Long.Transform != nil {
+			return opt.Long.Transform
+		}
+		return g.transforms[opt.C]
+	}
+	for _, o := range g.longOptions {
+		if o.Flag == nil && o.Val == opt.C && o.Transform != nil {
+			return o.Transform
+		}
+	}
+	return g.transforms[opt.C]
+}
+
+// validator returns the function that checks the arguments of opt, or nil if there is none. A short option uses the
+// validator of a long option whose Flag is nil and whose Val is the short option's character.
+func (g *Getopt) validator(opt *Opt) func(string) error {
+	if opt.Long != nil {
+		return opt.Long.Validate
+	}
+	for _, o := range g.longOptions {
+		if o.Flag == nil && o.Val == opt.C && o.Validate != nil {
+			return o.Validate
+		}
+	}
+	return nil
+}
+
+// validate checks the argument of opt, if one was given, with the option's validator. It returns an
+// [InvalidArgumentError] if the argument is rejected.
+func (g *Getopt) validate(opt *Opt) error {
+	if !opt.ArgGiven || g.isInOrder(opt) {
+		return nil
+	}
+	check := g.validator(opt)
+	if check == nil {
+		return nil
+	}
+	values := opt.Args
+	if values == nil {
+		values = []string{*opt.Arg}
+	}
+	for _, arg := range values {
+		if err := check(arg); err != nil {
+			option, prefix := opt.name()
+			return InvalidArgumentError{
+				Option: option,
+				Prefix: prefix,
+				Arg:    arg,
+				Err:    err,
+			}
+		}
+	}
+	return nil
+}
+
+// nargs returns the number of arguments that opt takes according to [Option.NArgs]. A short option uses the NArgs of a
+// long option whose Flag is nil and whose Val is the short option's character.
+func (g *Getopt) nargs(opt *Opt) int {
+	if opt.Long != nil {
+		return opt.Long.NArgs
+	}
+	for _, o := range g.longOptions {
+		if o.Flag == nil && o.Val == opt.C && o.NArgs > 1 {
+			return o.NArgs
+		}
+	}
+	return 0
+}
+
+// checkSeparate returns an [AttachedArgumentError] if opt has an attached argument but requires a separate one,
+// according to [Option.SeparateArg]. A short option uses the setting of a long option whose Flag is nil and whose Val
+// is the short option's character.
+func (g *Getopt) checkSeparate(opt *Opt) error {
+	if !opt.Attached || g.isInOrder(opt) {
+		return nil
+	}
+	separate := opt.Long != nil && opt.Long.SeparateArg
+	if opt.Long == nil {
+		separate = slices.ContainsFunc(g.longOptions, func(o Option) bool {
+			return o.Flag == nil && o.Val == opt.C && o.SeparateArg
+		})
+	}
+	if !separate {
+		return nil
+	}
+	option, prefix := opt.name()
+	return AttachedArgumentError{
+		Option: option,
+		Prefix: prefix,
+		Arg:    *opt.Arg,
+	}
+}
+
+// collectArgs takes the rest of the arguments of an option with more than one, according to [Option.NArgs], from the
+// elements following it, and stores them all in opt.Args.
+func (g *Getopt) collectArgs(opt *Opt) error {
+	if !opt.ArgGiven || g.isInOrder(opt) || opt.Long != nil && opt.Long.Greedy {
+		return nil
+	}
+	n := g.nargs(opt)
+	if n <= 1 {
+		return nil
+	}
+	values := make([]string, 1, n)
+	values[0] = *opt.Arg
+	for len(values) < n {
+		if g.noSeparateArgument() {
+			option, prefix := opt.name()
+			return ArgumentRequiredError{
+				Option: option,
+				Prefix: prefix,
+			}
+		}
+		values = append(values, g.Args[g.optind])
+		g.optind++
+	}
+	opt.Args = values
+	return nil
+}
+
+// scan finds the next option and records it in the parser's bookkeeping before returning it.
+func (g *Getopt) scan(longOnly bool) (*Opt, error) {
+	var opt Opt
+	if found, err := g.scanInto(longOnly, &opt); !found {
+		return nil, err
+	}
+	return &opt, nil
 }
 
-// nonoption tests whether ARGV[optind] holds a non-option argument.
-func nonoption(s string) bool {
-	return !strings.HasPrefix(s, dash) || len(s) == 1
+// scanInto is like scan, but it stores the option in opt instead of allocating a new one. It reports whether it found
+// an option. Otherwise, opt is left zeroed.
+func (g *Getopt) scanInto(longOnly bool, opt *Opt) (bool, error) {
+	*opt = Opt{}
+	found, err := g.getoptInternal(longOnly, opt)
+	for g.unknownHandler != nil {
+		unrecog, ok := err.(UnrecognizedOptionError)
+		if !ok {
+			break
+		}
+		if err = g.unknownHandler(unrecog.Prefix + unrecog.Option); err != nil {
+			break
+		}
+		found, err = g.getoptInternal(longOnly, opt)
+	}
+	if found {
+		if err = g.checkSeparate(opt); err == nil {
+			err = g.collectArgs(opt)
+		}
+		if err == nil {
+			g.transform(opt)
+			err = g.validate(opt)
+		}
+		found = err == nil
+	}
+	if found && slices.Contains(g.singleton, opt.C) && g.counts[opt.C]+opt.Repeat > 1 {
+		err = DuplicateUseError{Val: opt.C}
+		found = false
+	}
+	if found {
+		if opt.Long != nil && opt.Long.Greedy {
+			// Copy the arguments, since Args will be permuted when scanning resumes.
+			opt.Args = slices.Clone(g.Args[g.optind:])
+			g.optind = len(g.Args)
+		}
+		g.record(opt)
+	} else {
+		*opt = Opt{}
+	}
+	if err != nil {
+		err = PositionedError{Index: g.current, Err: err}
+	}
+	if err != nil && g.Opterr && !errors.Is(err, ErrHelpRequested) && !errors.Is(err, ErrVersionRequested) {
+		out := g.Out
+		if out == nil {
+			out = os.Stderr
+		}
+		_, _ = fmt.Fprintln(out, err.Error())
+	}
+	return found, err
 }
 
-func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
+func (g *Getopt) getoptInternal(longOnly bool, opt *Opt) (bool, error) {
 	if len(g.Args) < 1 {
-		return nil, nil
+		return false, nil
 	}
 
 	if len(g.nextChar) == 0 {
@@ -383,7 +1586,9 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 			g.firstNonopt = g.optind
 		}
 
-		if g.shortOptions.Ordering == Permute {
+		ordering := g.Ordering()
+		// Non-options may have been skipped even with another ordering if the ordering was changed during the scan.
+		if ordering == Permute || g.firstNonopt != g.lastNonopt {
 			// If we have just processed some options following some non-options, exchange them so that the options come
 			// first.
 			if g.firstNonopt != g.lastNonopt && g.lastNonopt != g.optind {
@@ -391,9 +1596,11 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 			} else if g.lastNonopt != g.optind {
 				g.firstNonopt = g.optind
 			}
+		}
 
+		if ordering == Permute {
 			// Skip any additional non-options and extend the range of non-options previously skipped.
-			for g.optind < len(g.Args) && nonoption(g.Args[g.optind]) {
+			for g.optind < len(g.Args) && g.nonoption(g.Args[g.optind]) {
 				g.optind++
 			}
 			g.lastNonopt = g.optind
@@ -401,8 +1608,11 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 
 		// The special ARGV-element '--' means premature end of options. Skip it like a null option, then exchange with
 		// previous non-options as if it were an option, then skip everything else like a non-option.
-		if g.optind != len(g.Args) && g.Args[g.optind] == argumentTerminator {
+		if g.optind != len(g.Args) && g.isTerminator(g.Args[g.optind]) {
+			// Nothing at or after optind has been permuted yet, so this is also the terminator's original index.
+			g.terminator = g.optind
 			g.optind++
+			g.tailStart = g.optind
 
 			if g.firstNonopt != g.lastNonopt && g.lastNonopt != g.optind {
 				g.exchange()
@@ -422,31 +1632,84 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 			if g.firstNonopt != g.lastNonopt {
 				g.optind = g.firstNonopt
 			}
-			return nil, nil
+			g.finishScan()
+			return false, nil
 		}
 
 		// If we have come to a non-option and did not permute it, either stop the scan or describe it to the caller and
 		// pass it by.
-		if nonoption(g.Args[g.optind]) {
-			if g.shortOptions.Ordering == RequireOrder {
-				return nil, nil
+		if g.nonoption(g.Args[g.optind]) {
+			if ordering == RequireOrder {
+				// Include any non-options skipped before the ordering changed.
+				if g.firstNonopt != g.lastNonopt {
+					g.optind = g.firstNonopt
+				}
+				g.finishScan()
+				return false, nil
 			}
 			arg := &g.Args[g.optind]
 			g.optind++
-			return &Opt{
-				C:       1,
-				LongInd: -1,
-				Arg:     arg,
-			}, nil
+			*opt = Opt{
+				C:        g.inOrderRune,
+				LongInd:  -1,
+				Arg:      arg,
+				ArgGiven: true,
+				Repeat:   1,
+			}
+			return true, nil
 		}
 
 		// We have found another option-ARGV-element. Check whether it might be a long option.
-		if len(g.longOptions) > 0 {
-			if []rune(g.Args[g.optind])[1] == '-' {
+		// Nothing at or after optind has been permuted yet, so this is also the element's original index.
+		g.current = g.optind
+		first, size := decodeRune(g.Args[g.optind])
+		g.introducer = g.Args[g.optind][:size]
+
+		// An element such as "+%Y" is returned whole when SetPlusIntroducer has enabled it.
+		if g.isPlus(first) {
+			arg := g.Args[g.optind][size:]
+			g.optind++
+			*opt = Opt{
+				C:        PlusOption,
+				LongInd:  -1,
+				Arg:      &arg,
+				ArgGiven: true,
+				Attached: true,
+				Repeat:   1,
+			}
+			return true, nil
+		}
+
+		// With numeric options enabled, an element such as "-10" is a number, not a cluster of digit options.
+		if g.shortOptions.Numeric && isNumber(g.Args[g.optind][size:]) {
+			arg := g.Args[g.optind][size:]
+			g.optind++
+			*opt = Opt{
+				C:        NumberOption,
+				LongInd:  -1,
+				Arg:      &arg,
+				ArgGiven: true,
+				Attached: true,
+				Repeat:   1,
+			}
+			return true, nil
+		}
+		if g.rejectLong {
+			if n := g.longPrefixLen(g.Args[g.optind]); n != 0 {
+				err := UnrecognizedOptionError{
+					Option: g.Args[g.optind][n:],
+					Prefix: g.Args[g.optind][:n],
+				}
+				g.optind++
+				return false, err
+			}
+		}
+		if g.hasLongOptions() {
+			if n := g.longPrefixLen(g.Args[g.optind]); n != 0 {
 				// "--foo" is always a long option. The
 				// special option "--" was handled above.
-				g.nextChar = []rune(g.Args[g.optind])[len(argumentTerminator):]
-				return g.processLongOption(longOnly, argumentTerminator)
+				g.nextChar = g.Args[g.optind][n:]
+				return g.processLongOption(longOnly, g.Args[g.optind][:n], opt)
 			}
 
 			// If longOnly and the ARGV-element has the form "-f", where f is a valid short option, don't consider it an
@@ -457,23 +1720,34 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 			// abbreviation of the long option, just like "--fu", and not "-f" with arg "u".
 			//
 			// This distinction seems to be the most useful approach.
-			if longOnly && (len(g.Args[g.optind]) > 1 || !g.shortOptions.HasOpt([]rune(g.Args[g.optind])[1])) {
-				g.nextChar = []rune(g.Args[g.optind])[1:]
-				opt, err := g.processLongOption(longOnly, dash)
-				if opt != nil {
-					return opt, err
+			element := g.Args[g.optind][len(g.introducer):]
+			if c, size := decodeRune(element); longOnly && (len(element) > size || !g.shortOptions.HasOpt(c)) {
+				g.nextChar = element
+				// Neither an option nor an error means the element should be handled as short options instead.
+				found, err := g.processLongOption(longOnly, g.introducer, opt)
+				if found || err != nil {
+					return found, err
 				}
 			}
 		}
 
 		// It is not a long option. Skip the initial punctuation.
-		g.nextChar = []rune(g.Args[g.optind])[1:]
+		g.nextChar = g.Args[g.optind][len(g.introducer):]
 	}
 
 	// Look at and handle the next short option-character.
 
-	c := g.nextChar[0]
-	g.nextChar = g.nextChar[1:]
+	c, size := decodeRune(g.nextChar)
+	g.nextChar = g.nextChar[size:]
+
+	// Consume any immediate repetitions of a flag, such as "-vvv", when requested.
+	repeat := 1
+	if g.collapseRepeats && g.isFlag(c) {
+		for len(g.nextChar) > 0 && firstRune(g.nextChar) == c {
+			g.nextChar = g.nextChar[size:]
+			repeat++
+		}
+	}
 
 	// Increment 'optind' when we start to process its last character.
 	if len(g.nextChar) == 0 {
@@ -481,58 +1755,71 @@ func (g *Getopt) getoptInternal(longOnly bool) (*Opt, error) {
 	}
 
 	if !g.shortOptions.HasOpt(c) {
-		return nil, UnrecognizedOptionError{
+		return false, UnrecognizedOptionError{
 			Option: string(c),
-			prefix: dash,
+			Prefix: g.introducer,
 		}
 	}
 
 	// Convenience. Treat POSIX -W foo same as long option --foo
-	if c == 'W' && g.shortOptions.W && len(g.longOptions) > 0 {
-		// This is an option that requires an argument.
+	if c == 'W' && g.shortOptions.W && g.hasLongOptions() {
+		// This is an option that requires an argument, which must name a long option.
+		wOption := WOptionError{Prefix: g.introducer + string(c)}
 		if len(g.nextChar) == 0 {
 			if g.optind == len(g.Args) {
-				return nil, ArgumentRequiredError{
-					Option: string(c),
-					prefix: dash,
-				}
+				return false, wOption
 			}
-			g.nextChar = []rune(g.Args[g.optind])
+			g.nextChar = g.Args[g.optind]
+			g.current = g.optind
+		}
+		if len(g.nextChar) == 0 || firstRune(g.nextChar) == g.longSeparator {
+			// There's no name, as in "-W ''" or "-W=x". Consume the argument and report it the same way.
+			g.nextChar = ""
+			g.optind++
+			return false, wOption
 		}
 
-		return g.processLongOption(false /* longOnly */, "-W ")
+		return g.processLongOption(false /* longOnly */, g.introducer+"W ", opt)
 	}
 
 	var arg *string
+	attached := false
 	switch d, _ := g.shortOptions.Opts[c]; d {
 	case OptionalArgument:
+		// As with long options, the next element is never taken as the argument, whatever the ordering.
 		if len(g.nextChar) != 0 {
-			s := string(g.nextChar)
+			s := g.nextChar
 			arg = &s
+			attached = true
 			g.optind++
 		}
-		g.nextChar = nil
+		g.nextChar = ""
 	case RequiredArgument:
 		if len(g.nextChar) != 0 {
-			s := string(g.nextChar)
+			s := g.nextChar
 			arg = &s
+			attached = true
 			// We've ended this ARGV-element by taking the rest as an arg. We must advance to the next element now.
 			g.optind++
-		} else if g.optind == len(g.Args) {
-			return nil, ArgumentRequiredError{
+		} else if g.noSeparateArgument() {
+			return false, ArgumentRequiredError{
 				Option: string(c),
-				prefix: dash,
+				Prefix: g.introducer,
 			}
 		} else {
 			// We already incremented 'optind' once; increment it again when taking next ARGV-elt as argument.
 			arg = &g.Args[g.optind]
 			g.optind++
 		}
-		g.nextChar = nil
+		g.nextChar = ""
+	}
+	*opt = Opt{
+		C:        c,
+		LongInd:  -1,
+		Arg:      arg,
+		ArgGiven: arg != nil,
+		Attached: attached,
+		Repeat:   repeat,
 	}
-	return &Opt{
-		C:       c,
-		LongInd: -1,
-		Arg:     arg,
-	}, nil
+	return true, nil
 }
@@ -1,9 +1,18 @@
 package getopt_test
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	"github.com/onsi/gomega/types"
 	. "github.com/rkennedy/go-getopt"
 )
 
@@ -179,10 +188,2399 @@ var _ = Describe("Getopt", func() {
 				{Name: "opt", HasArg: RequiredArgument},
 			})
 			Expect(gopt.Getopt()).To(HaveValue(MatchAllFields(Fields{
-				"C":       Equal(rune(0)),
-				"Arg":     HaveValue(Equal("arg")),
+				"C":        Equal(rune(0)),
+				"Arg":      HaveValue(Equal("arg")),
+				"ArgGiven": BeTrue(),
+				"LongInd":  Equal(0),
+				"Long":     PointTo(MatchFields(IgnoreExtras, Fields{"Name": Equal("opt")})),
+				"Attached": BeFalse(),
+				"Repeat":   Equal(1),
+				"Args":     BeNil(),
+			})))
+		})
+	})
+})
+
+var _ = Describe("SetOrdering", func() {
+	It("detects conflict with spec prefix", func() {
+		gopt := New([]string{"program", "a", "-b"}, "+b")
+		Expect(gopt.SetOrdering(ReturnInOrder)).To(MatchError(OrderingConflictError{
+			Requested: ReturnInOrder,
+			Spec:      RequireOrder,
+		}))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(rune(1)),
+			"Arg": HaveValue(Equal("a")),
+		})))
+	})
+
+	It("accepts the ordering the prefix selected", func() {
+		gopt := New([]string{"program"}, "-b")
+		Expect(gopt.SetOrdering(ReturnInOrder)).To(Succeed())
+	})
+
+	DescribeTable("accepts any ordering without a prefix",
+		func(ordering Ordering) {
+			gopt := New([]string{"program"}, "b")
+			Expect(gopt.SetOrdering(ordering)).To(Succeed())
+		},
+		Entry(nil, RequireOrder),
+		Entry(nil, Permute),
+		Entry(nil, ReturnInOrder),
+	)
+
+	It("stops at first non-option with RequireOrder", func() {
+		gopt := New([]string{"program", "a", "-b"}, "b")
+		Expect(gopt.SetOrdering(RequireOrder)).To(Succeed())
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Optind()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Error classification", func() {
+	longopts := []Option{
+		{Name: "alpha", HasArg: NoArgument, Val: 'a'},
+		{Name: "alpine", HasArg: NoArgument, Val: 'p'},
+		{Name: "bravo", HasArg: RequiredArgument, Val: 'b'},
+		{Name: "charlie", HasArg: RequiredArgument, Val: 'c', SeparateArg: true},
+	}
+
+	DescribeTable("matches sentinels",
+		func(argv []string, sentinel error, others ...error) {
+			gopt := NewLong(argv, "ab:", longopts)
+			_, err := gopt.Getopt()
+			Expect(err).To(MatchError(sentinel))
+			for _, other := range others {
+				Expect(errors.Is(err, other)).To(BeFalse())
+			}
+		},
+		Entry("ambiguous", []string{"program", "--alp"}, ErrAmbiguous, ErrUnrecognized),
+		Entry("unrecognized short", []string{"program", "-x"}, ErrUnrecognized, ErrAmbiguous),
+		Entry("unrecognized long", []string{"program", "--xray"}, ErrUnrecognized, ErrArgumentRequired),
+		Entry("argument not allowed", []string{"program", "--alpha=x"}, ErrArgumentNotAllowed, ErrArgumentRequired),
+		Entry("argument required", []string{"program", "-b"}, ErrArgumentRequired, ErrArgumentNotAllowed),
+		Entry("argument required (long)", []string{"program", "--bravo"}, ErrArgumentRequired, ErrUnrecognized),
+		Entry("attached argument", []string{"program", "--charlie=x"}, ErrAttachedArgument, ErrArgumentNotAllowed),
+	)
+})
+
+var _ = Describe("Remaining", func() {
+	It("returns operands after parsing", func() {
+		gopt := New([]string{"program", "x", "-a", "y"}, "a")
+		for opt, err := gopt.Getopt(); opt != nil || err != nil; opt, err = gopt.Getopt() { //revive:disable-line:empty-block,line-length-limit
+		}
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y"))
+	})
+
+	It("returns an empty slice when everything was consumed", func() {
+		gopt := New([]string{"program", "-a"}, "a")
+		for opt, err := gopt.Getopt(); opt != nil || err != nil; opt, err = gopt.Getopt() { //revive:disable-line:empty-block,line-length-limit
+		}
+		Expect(gopt.Remaining()).NotTo(BeNil())
+		Expect(gopt.Remaining()).To(BeEmpty())
+	})
+
+	It("returns unscanned arguments before parsing finishes", func() {
+		gopt := New([]string{"program", "-a", "-b", "x"}, "ab")
+		Expect(gopt.Remaining()).To(HaveExactElements("-a", "-b", "x"))
+		Expect(gopt.Getopt()).NotTo(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("-b", "x"))
+	})
+
+	It("handles an empty argument list", func() {
+		gopt := New(nil, "a")
+		Expect(gopt.Remaining()).NotTo(BeNil())
+		Expect(gopt.Remaining()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Count", func() {
+	It("counts repeated flags across arguments", func() {
+		gopt := NewLong([]string{"program", "-vvv", "x", "-qv", "--verbose"}, "vq", []Option{
+			{Name: "verbose", Val: 'v'},
+		})
+		Expect(gopt.Count('v')).To(Equal(0))
+		for opt, err := gopt.Getopt(); opt != nil || err != nil; opt, err = gopt.Getopt() { //revive:disable-line:empty-block,line-length-limit
+		}
+		Expect(gopt.Count('v')).To(Equal(5))
+		Expect(gopt.Count('q')).To(Equal(1))
+		Expect(gopt.Count('z')).To(Equal(0))
+	})
+
+	It("counts only what has been parsed so far", func() {
+		gopt := New([]string{"program", "-vv", "-v"}, "v")
+		Expect(gopt.Getopt()).NotTo(BeNil())
+		Expect(gopt.Count('v')).To(Equal(1))
+	})
+})
+
+var _ = Describe("SetCollapseRepeats", func() {
+	It("returns each occurrence by default", func() {
+		gopt := New([]string{"program", "-vvv"}, "v")
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('v'),
+			"Repeat": Equal(1),
+		})))
+		Expect(gopt.Optind()).To(Equal(1))
+	})
+
+	It("collapses repeated flags", func() {
+		gopt := New([]string{"program", "-vvvqv", "-v", "x"}, "qv")
+		gopt.SetCollapseRepeats(true)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('v'),
+			"Repeat": Equal(3),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('q'),
+			"Repeat": Equal(1),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('v'),
+			"Repeat": Equal(1),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('v'),
+			"Repeat": Equal(1),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Count('v')).To(Equal(5))
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+
+	It("does not collapse options with arguments", func() {
+		gopt := New([]string{"program", "-aab"}, "a:b")
+		gopt.SetCollapseRepeats(true)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('a'),
+			"Arg":    HaveValue(Equal("ab")),
+			"Repeat": Equal(1),
+		})))
+	})
+})
+
+var _ = Describe("Negatable options", func() {
+	longopts := []Option{
+		{Name: "foo", Val: 'f', Negatable: true},
+		{Name: "nofoo", Val: 'n'},
+	}
+
+	DescribeTable("match both forms",
+		func(arg string, expected string) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":       Equal('f'),
+				"Arg":     HaveValue(Equal(expected)),
 				"LongInd": Equal(0),
 			})))
+		},
+		Entry(nil, "--foo", "true"),
+		Entry(nil, "--fo", "true"),
+		Entry(nil, "--no-foo", "false"),
+		Entry(nil, "--no-fo", "false"),
+		Entry(nil, "--no-", "false"),
+	)
+
+	It("matches a similarly named option exactly", func() {
+		gopt := NewLong([]string{"program", "--nofo"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('n'),
+			"Arg": BeNil(),
+		})))
+	})
+
+	It("treats the negated form as a distinct name", func() {
+		gopt := NewLong([]string{"program", "--no"}, "", longopts)
+		Expect(gopt.Getopt()).Error().To(MatchError("option '--no' is ambiguous; possibilities: '--no-foo' '--nofoo'"))
+	})
+
+	It("rejects an argument", func() {
+		gopt := NewLong([]string{"program", "--no-foo=x"}, "", longopts)
+		Expect(gopt.Getopt()).Error().To(MatchError("option '--no-foo' doesn't allow an argument"))
+	})
+
+	It("does not negate ordinary options", func() {
+		gopt := NewLong([]string{"program", "--no-nofoo"}, "", longopts)
+		Expect(gopt.Getopt()).Error().To(MatchError(ErrUnrecognized))
+	})
+})
+
+var _ = Describe("SetPrefixes", func() {
+	longopts := []Option{
+		{Name: "charlie", HasArg: RequiredArgument, Val: 'c'},
+	}
+
+	It("mixes introducers", func() {
+		argv := []string{"program", "/a", "x", "-b", "//charlie", "1", "/-", "--charlie=2", "/"}
+		gopt := NewLong(argv, "abc:", longopts)
+		gopt.SetPrefixes('-', '/')
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('b')})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":       Equal('c'),
+			"Arg":     HaveValue(Equal("1")),
+			"LongInd": Equal(0),
+		})))
+		Expect(gopt.Getopt()).Error().To(MatchError("unrecognized option '/-'"))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": HaveValue(Equal("2")),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "/"))
+	})
+
+	It("recognizes a doubled introducer as a terminator", func() {
+		gopt := New([]string{"program", "/a", "//", "/b", "-a"}, "ab")
+		gopt.SetPrefixes('/')
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("/b", "-a"))
+	})
+
+	It("replaces the default introducer", func() {
+		gopt := New([]string{"program", "-a", "/b"}, "ab")
+		gopt.SetPrefixes('/')
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('b')})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("-a"))
+	})
+
+	It("reports the introducer in errors", func() {
+		gopt := NewLong([]string{"program", "/x", "//delta", "/c"}, "c:", longopts)
+		gopt.SetPrefixes('/')
+		Expect(gopt.Getopt()).Error().To(MatchError("unrecognized option '/x'"))
+		Expect(gopt.Getopt()).Error().To(MatchError("unrecognized option '//delta'"))
+		Expect(gopt.Getopt()).Error().To(MatchError("option '/c' requires an argument"))
+	})
+
+	It("restores the default", func() {
+		gopt := New([]string{"program", "-a"}, "a")
+		gopt.SetPrefixes('/')
+		gopt.SetPrefixes()
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+	})
+})
+
+var _ = Describe("Opt.Long", func() {
+	It("points at the matched option", func() {
+		longopts := []Option{
+			{Name: "alpha", Val: 'a'},
+			{Name: "bravo", Val: 'b'},
+		}
+		gopt := NewLong([]string{"program", "--br", "-a"}, "a", longopts)
+		opt, err := gopt.Getopt()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt.Long).To(BeIdenticalTo(&longopts[1]))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":    Equal('a'),
+			"Long": BeNil(),
+		})))
+	})
+})
+
+var _ = Describe("NewStrict", func() {
+	It("rejects duplicate options", func() {
+		gopt, err := NewStrict([]string{"program"}, "abca")
+		Expect(err).To(MatchError("option '-a' is defined more than once"))
+		Expect(gopt).To(BeNil())
+	})
+
+	It("accepts a valid spec", func() {
+		gopt, err := NewStrict([]string{"program", "-b", "x"}, "ab:")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('b'),
+			"Arg": HaveValue(Equal("x")),
+		})))
+	})
+})
+
+var _ = Describe("Opterr", func() {
+	It("prints nothing by default", func() {
+		var out bytes.Buffer
+		gopt := New([]string{"program", "-x"}, "a")
+		gopt.Out = &out
+		Expect(gopt.Getopt()).Error().To(HaveOccurred())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("prints errors when enabled", func() {
+		var out bytes.Buffer
+		gopt := New([]string{"program", "-x", "-a", "-b"}, "ab:")
+		gopt.Opterr = true
+		gopt.Out = &out
+		Expect(gopt.Getopt()).Error().To(MatchError("unrecognized option '-x'"))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).Error().To(MatchError("option '-b' requires an argument"))
+		Expect(out.String()).To(Equal("unrecognized option '-x'\noption '-b' requires an argument\n"))
+	})
+})
+
+var _ = Describe("Accumulate", func() {
+	It("collects short option arguments", func() {
+		var includes []string
+		gopt := New([]string{"program", "-I", "a", "-Ib", "x", "-I", "c"}, "I:")
+		gopt.Accumulate('I', &includes)
+		var seen int
+		for opt := range gopt.All() {
+			Expect(opt.C).To(Equal('I'))
+			seen++
+		}
+		Expect(seen).To(Equal(3))
+		Expect(includes).To(HaveExactElements("a", "b", "c"))
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+
+	It("collects long option arguments", func() {
+		var defines, includes []string
+		longopts := []Option{
+			{Name: "define", HasArg: RequiredArgument, Val: 'D', Accumulate: &defines},
+			{Name: "include", HasArg: RequiredArgument, Val: 'I'},
+		}
+		gopt := NewLong([]string{"program", "--define=a", "-Db", "--include", "c", "-Id"}, "D:I:", longopts)
+		gopt.Accumulate('I', &includes)
+		for range gopt.All() { //revive:disable-line:empty-block
+		}
+		Expect(defines).To(HaveExactElements("a", "b"))
+		Expect(includes).To(HaveExactElements("c", "d"))
+	})
+
+	It("collects short and long forms into the option's slice", func() {
+		var includes, registered []string
+		longopts := []Option{
+			{Name: "include", HasArg: RequiredArgument, Val: 'I', Accumulate: &includes},
+		}
+		gopt := NewLong([]string{"program", "-I", "a", "--include", "b", "-Ic"}, "I:", longopts)
+		gopt.Accumulate('I', &registered)
+		for range gopt.All() { //revive:disable-line:empty-block
+		}
+		Expect(includes).To(HaveExactElements("a", "b", "c"))
+		Expect(registered).To(BeEmpty())
+	})
+
+	It("skips occurrences without arguments", func() {
+		var values []string
+		gopt := New([]string{"program", "-o", "-ox"}, "o::")
+		gopt.Accumulate('o', &values)
+		for range gopt.All() { //revive:disable-line:empty-block
+		}
+		Expect(values).To(HaveExactElements("x"))
+	})
+
+	It("can be unregistered", func() {
+		var values []string
+		gopt := New([]string{"program", "-ox"}, "o:")
+		gopt.Accumulate('o', &values)
+		gopt.Accumulate('o', nil)
+		for range gopt.All() { //revive:disable-line:empty-block
+		}
+		Expect(values).To(BeEmpty())
+	})
+})
+
+var _ = Describe("StopAtFirstNonOption", func() {
+	It("stops at the next non-option", func() {
+		gopt := New([]string{"program", "-a", "sub", "-b"}, "ab")
+		gopt.StopAtFirstNonOption(true)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("sub", "-b"))
+	})
+
+	It("keeps non-options skipped before the switch", func() {
+		gopt := New([]string{"program", "x", "-a", "y", "-c", "sub", "-b"}, "abc")
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('c')})))
+		gopt.StopAtFirstNonOption(true)
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y", "sub", "-b"))
+	})
+
+	It("can be switched off again", func() {
+		gopt := New([]string{"program", "-a", "x", "-b"}, "ab")
+		gopt.StopAtFirstNonOption(true)
+		gopt.StopAtFirstNonOption(false)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('b')})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+
+	It("overrides ReturnInOrder", func() {
+		gopt := New([]string{"program", "x", "-a"}, "-a")
+		gopt.StopAtFirstNonOption(true)
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "-a"))
+	})
+})
+
+var _ = Describe("TerminatorIndex", func() {
+	It("reports the original position", func() {
+		argv := []string{"program", "x", "-a", "y", "-b", "--", "-c", "z"}
+		original := slices.Clone(argv)
+		gopt := New(argv, "abc")
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+		parseAll(gopt)
+		Expect(gopt.TerminatorIndex()).To(Equal(5))
+		Expect(original[gopt.TerminatorIndex()+1:]).To(HaveExactElements("-c", "z"))
+		Expect(gopt.Args).NotTo(Equal(original))
+	})
+
+	It("reports -1 without a terminator", func() {
+		gopt := New([]string{"program", "x", "-a"}, "a")
+		parseAll(gopt)
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+	})
+
+	It("ignores a terminator used as an argument", func() {
+		gopt := New([]string{"program", "-o", "--", "x"}, "o:")
+		parseAll(gopt)
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+	})
+})
+
+var _ = Describe("SetTerminator", func() {
+	It("can disable the terminator", func() {
+		gopt := New([]string{"program", "x", "--", "-a", "--", "y"}, "a")
+		gopt.SetTerminator("")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+		Expect(gopt.Operands()).To(HaveExactElements("x", "--", "--", "y"))
+	})
+
+	It("returns a disabled terminator in order", func() {
+		gopt := New([]string{"program", "--", "-a"}, "-a")
+		gopt.SetTerminator("")
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("--"))))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+	})
+
+	It("stops at a disabled terminator in RequireOrder", func() {
+		gopt := New([]string{"program", "-a", "--", "-a"}, "+a")
+		gopt.SetTerminator("")
+		parseAll(gopt)
+		Expect(gopt.Count('a')).To(Equal(1))
+		Expect(gopt.Remaining()).To(HaveExactElements("--", "-a"))
+	})
+
+	DescribeTable("replaces the terminator",
+		func(opts string) {
+			gopt := New([]string{"program", "x", "--", "-a", "end", "-a", "y"}, opts)
+			gopt.SetTerminator("end")
+			parseAll(gopt)
+			Expect(gopt.Count('a')).To(Equal(1))
+			Expect(gopt.TerminatorIndex()).To(Equal(4))
+			Expect(gopt.Operands()).To(HaveExactElements("x", "--", "-a", "y"))
+		},
+		Entry("when permuting", "a"),
+		Entry("when returning in order", "-a"),
+	)
+
+	It("disables doubled introducers", func() {
+		gopt := New([]string{"program", "//", "/a", "--", "-a"}, "a")
+		gopt.SetPrefixes('-', '/')
+		gopt.SetTerminator("---")
+		parseAll(gopt)
+		Expect(gopt.Count('a')).To(Equal(2))
+		Expect(gopt.Remaining()).To(HaveExactElements("//", "--"))
+	})
+})
+
+var _ = Describe("SetPreserveAfterTerminator", func() {
+	argv := func() []string {
+		return []string{"program", "x", "-a", "y", "--", "-b", "z"}
+	}
+
+	It("includes the tail in the operands by default", func() {
+		gopt := New(argv(), "ab")
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y", "-b", "z"))
+		Expect(gopt.Tail()).To(HaveExactElements("-b", "z"))
+	})
+
+	It("separates the tail when enabled", func() {
+		gopt := New(argv(), "ab")
+		gopt.SetPreserveAfterTerminator(true)
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y"))
+		Expect(gopt.Tail()).To(HaveExactElements("-b", "z"))
+	})
+
+	It("returns an empty tail without a terminator", func() {
+		gopt := New([]string{"program", "x", "-a"}, "a")
+		gopt.SetPreserveAfterTerminator(true)
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+		Expect(gopt.Tail()).NotTo(BeNil())
+		Expect(gopt.Tail()).To(BeEmpty())
+	})
+
+	It("returns an empty tail after a trailing terminator", func() {
+		gopt := New([]string{"program", "x", "--"}, "a")
+		gopt.SetPreserveAfterTerminator(true)
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+		Expect(gopt.Tail()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Optional long option arguments", func() {
+	longopts := []Option{
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+		{Name: "debug", HasArg: NoArgument, Val: 'd'},
+	}
+
+	It("takes an attached argument", func() {
+		gopt := NewLong([]string{"program", "--color=auto"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": HaveValue(Equal("auto")),
+		})))
+	})
+
+	DescribeTable("never takes the next argument",
+		func(opts string, expectedRemaining ...string) {
+			gopt := NewLong([]string{"program", "--color", "auto", "--debug"}, opts, longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":   Equal('c'),
+				"Arg": BeNil(),
+			})))
+			parseAll(gopt)
+			Expect(gopt.Remaining()).To(HaveExactElements(expectedRemaining))
+		},
+		Entry("permute", "", "auto"),
+		Entry("require order", "+", "auto", "--debug"),
+		Entry("return in order", "-"),
+	)
+
+	It("returns the next argument in order", func() {
+		gopt := NewLong([]string{"program", "--color", "auto"}, "-", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": BeNil(),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(rune(1)),
+			"Arg": HaveValue(Equal("auto")),
+		})))
+	})
+
+	It("never takes the next argument through -W", func() {
+		gopt := NewLong([]string{"program", "-W", "color", "auto"}, "W;", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": BeNil(),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("auto"))
+	})
+})
+
+var _ = Describe("Opt.Attached", func() {
+	longopts := []Option{
+		{Name: "out", HasArg: RequiredArgument, Val: 'o'},
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+	}
+
+	DescribeTable("reports where the argument came from",
+		func(argv []string, attached bool) {
+			gopt := NewLong(append([]string{"program"}, argv...), "o:c::W;", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"Arg":      HaveValue(Equal("file")),
+				"Attached": Equal(attached),
+			})))
+		},
+		Entry(nil, []string{"-ofile"}, true),
+		Entry(nil, []string{"-o", "file"}, false),
+		Entry(nil, []string{"--out=file"}, true),
+		Entry(nil, []string{"--out", "file"}, false),
+		Entry(nil, []string{"-cfile"}, true),
+		Entry(nil, []string{"--color=file"}, true),
+		Entry(nil, []string{"-W", "out=file"}, true),
+		Entry(nil, []string{"-W", "out", "file"}, false),
+	)
+
+	It("is false without an argument", func() {
+		gopt := NewLong([]string{"program", "--color"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"Arg":      BeNil(),
+			"Attached": BeFalse(),
+		})))
+	})
+})
+
+var _ = Describe("SetArgs", func() {
+	It("starts a fresh scan with the same options", func() {
+		gopt := NewLong([]string{"program", "x", "-v", "--", "y"}, "vo:", []Option{
+			{Name: "output", HasArg: RequiredArgument, Val: 'o', Required: true},
+		})
+		gopt.SetCollapseRepeats(true)
+		parseAll(gopt)
+		Expect(gopt.Count('v')).To(Equal(1))
+		Expect(gopt.TerminatorIndex()).To(Equal(3))
+
+		gopt.SetArgs([]string{"program", "-vv", "--out", "f", "z"})
+		Expect(gopt.Count('v')).To(Equal(0))
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('v'),
+			"Repeat": Equal(2),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('o'),
+			"Arg": HaveValue(Equal("f")),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("z"))
+		Expect(gopt.CheckRequired()).To(Succeed())
+	})
+
+	It("forgets options seen in the previous scan", func() {
+		gopt := New([]string{"program", "-a"}, "a")
+		gopt.Require('a')
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(Succeed())
+
+		gopt.SetArgs([]string{"program"})
+		parseAll(gopt)
+		Expect(gopt.CheckRequired()).To(MatchError(MissingRequiredOptionError{Names: []string{"-a"}}))
+	})
+})
+
+var _ = Describe("Rewind", func() {
+	It("scans the arguments again", func() {
+		gopt := NewLong([]string{"program", "-v", "--output", "f", "-v", "x"}, "vo:", []Option{
+			{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+		})
+		parseAll(gopt)
+		Expect(gopt.Count('v')).To(Equal(2))
+
+		gopt.Rewind()
+		Expect(gopt.Optind()).To(Equal(1))
+		Expect(gopt.Count('v')).To(Equal(0))
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("f"))))
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Count('v')).To(Equal(2))
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+
+	It("discards the rest of a partly scanned cluster", func() {
+		gopt := New([]string{"program", "-ab", "-c"}, "abc")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		gopt.Rewind()
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+	})
+
+	It("scans the permuted order", func() {
+		gopt := New([]string{"program", "x", "-a", "y", "--", "-b"}, "ab")
+		parseAll(gopt)
+		Expect(gopt.Permuted()).To(BeTrue())
+		permuted := slices.Clone(gopt.Args)
+		Expect(permuted).To(HaveExactElements("program", "-a", "--", "x", "y", "-b"))
+
+		gopt.Rewind()
+		Expect(gopt.Permuted()).To(BeFalse())
+		parseAll(gopt)
+		Expect(gopt.Args).To(Equal(permuted))
+		Expect(gopt.Permuted()).To(BeFalse())
+		Expect(gopt.Count('a')).To(Equal(1))
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y", "-b"))
+	})
+})
+
+var _ = Describe("Permuted", func() {
+	DescribeTable("reports whether arguments moved",
+		func(opts string, args []string, expected bool) {
+			gopt := New(append([]string{"program"}, args...), opts)
+			Expect(gopt.Permuted()).To(BeFalse())
+			parseAll(gopt)
+			Expect(gopt.Permuted()).To(Equal(expected))
+		},
+		Entry("options first", "ab:", []string{"-a", "-b", "1", "x", "y"}, false),
+		Entry("no options", "ab:", []string{"x", "y"}, false),
+		Entry("option after operand", "ab:", []string{"x", "-a", "y"}, true),
+		Entry("option at the end", "ab:", []string{"x", "-b", "1"}, true),
+		Entry("operand before terminator", "ab:", []string{"x", "--", "-a"}, true),
+		Entry("options after terminator", "ab:", []string{"-a", "--", "x", "-a"}, false),
+		Entry("RequireOrder", "+ab:", []string{"x", "-a"}, false),
+		Entry("ReturnInOrder", "-ab:", []string{"x", "-a", "y"}, false),
+	)
+
+	It("resets with SetArgs", func() {
+		gopt := New([]string{"program", "x", "-a"}, "a")
+		parseAll(gopt)
+		Expect(gopt.Permuted()).To(BeTrue())
+		gopt.SetArgs([]string{"program", "-a", "x"})
+		parseAll(gopt)
+		Expect(gopt.Permuted()).To(BeFalse())
+	})
+})
+
+var _ = Describe("SetOperandsSorted", func() {
+	DescribeTable("sorts only the operands",
+		func(opts string, args []string, preserve bool, expected []string, options string) {
+			gopt := NewLong(append([]string{"program"}, args...), opts, []Option{
+				{Name: "out", HasArg: RequiredArgument, Val: 'o'},
+			})
+			gopt.SetOperandsSorted(true)
+			gopt.SetPreserveAfterTerminator(preserve)
+			var seen []rune
+			for opt, err := range gopt.All() {
+				Expect(err).NotTo(HaveOccurred())
+				seen = append(seen, opt.C)
+			}
+			Expect(string(seen)).To(Equal(options))
+			Expect(gopt.Args).To(HaveExactElements(expected))
+		},
+		Entry("when permuting", "ao:", []string{"c", "-a", "b", "--out", "f", "a"}, false,
+			[]string{"program", "-a", "--out", "f", "a", "b", "c"}, "ao"),
+		Entry("including those after a terminator", "ao:", []string{"z", "-a", "--", "y", "-b", "x"}, false,
+			[]string{"program", "-a", "--", "-b", "x", "y", "z"}, "a"),
+		Entry("but not a preserved tail", "ao:", []string{"z", "w", "-a", "--", "y", "x"}, true,
+			[]string{"program", "-a", "--", "w", "z", "y", "x"}, "a"),
+		Entry("with RequireOrder", "+ao:", []string{"-a", "z", "y", "-a"}, false,
+			[]string{"program", "-a", "-a", "y", "z"}, "a"),
+		Entry("with nothing to sort", "ao:", []string{"-a"}, false,
+			[]string{"program", "-a"}, "a"),
+	)
+
+	It("is disabled by default", func() {
+		gopt := New([]string{"program", "b", "-a", "a"}, "a")
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("b", "a"))
+	})
+
+	It("keeps the permute observer in step", func() {
+		args := []string{"program", "d", "-a", "b", "c", "-a", "a", "--", "e", "b"}
+		gopt := New(slices.Clone(args), "a")
+		gopt.SetOperandsSorted(true)
+		origin := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		gopt.SetPermuteObserver(func(from, to int) {
+			Expect(to).To(BeNumerically("<", from))
+			i := origin[from]
+			origin = slices.Insert(slices.Delete(origin, from, from+1), to, i)
+		})
+		parseAll(gopt)
+		Expect(gopt.Remaining()).To(HaveExactElements("a", "b", "b", "c", "d", "e"))
+		for i, arg := range gopt.Args {
+			Expect(args[origin[i]]).To(Equal(arg))
+		}
+	})
+})
+
+var _ = Describe("SetPermuteObserver", func() {
+	type move struct{ from, to int }
+
+	DescribeTable("reports each moved element",
+		func(args []string, expected []move, permuted []string) {
+			gopt := New(args, "abc")
+			var moves []move
+			gopt.SetPermuteObserver(func(from, to int) {
+				moves = append(moves, move{from, to})
+			})
+			parseAll(gopt)
+			Expect(moves).To(HaveExactElements(expected))
+			Expect(gopt.Args).To(HaveExactElements(permuted))
+		},
+		Entry(nil, []string{"program", "x", "-a", "y"},
+			[]move{{2, 1}},
+			[]string{"program", "-a", "x", "y"}),
+		Entry("in several rearrangements", []string{"program", "x", "y", "-a", "-b", "z", "-c"},
+			[]move{{3, 1}, {4, 2}, {6, 3}},
+			[]string{"program", "-a", "-b", "-c", "x", "y", "z"}),
+		Entry("at a terminator", []string{"program", "x", "-a", "--", "y"},
+			[]move{{2, 1}, {3, 2}},
+			[]string{"program", "-a", "--", "x", "y"}),
+		Entry("with nothing to move", []string{"program", "-a", "x", "y"},
+			[]move(nil),
+			[]string{"program", "-a", "x", "y"}),
+	)
+
+	It("keeps a mapping to original positions in step", func() {
+		args := []string{"program", "x", "-a", "y", "z", "-bc", "w", "-a", "--", "v"}
+		gopt := New(slices.Clone(args), "abc")
+		origin := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		gopt.SetPermuteObserver(func(from, to int) {
+			i := origin[from]
+			origin = slices.Insert(slices.Delete(origin, from, from+1), to, i)
 		})
+		parseAll(gopt)
+		Expect(gopt.Permuted()).To(BeTrue())
+		for i, arg := range gopt.Args {
+			Expect(args[origin[i]]).To(Equal(arg))
+		}
+	})
+
+	It("can be removed", func() {
+		gopt := New([]string{"program", "x", "-a"}, "a")
+		gopt.SetPermuteObserver(func(int, int) { Fail("observer called") })
+		gopt.SetPermuteObserver(nil)
+		parseAll(gopt)
+		Expect(gopt.Permuted()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Rebind", func() {
+	It("parses subcommand options with a new specification", func() {
+		gopt := New([]string{"program", "-v", "commit", "-m", "msg", "-a", "file"}, "+v")
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("commit", "-m", "msg", "-a", "file"))
+
+		gopt.Rebind("m:a", []Option{{Name: "all", Val: 'a'}})
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('m'),
+			"Arg": HaveValue(Equal("msg")),
+		})))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("commit", "file"))
+	})
+
+	It("rejects options from the old specification", func() {
+		gopt := New([]string{"program", "-v", "sub", "-v"}, "+v")
+		parseAll(gopt)
+		gopt.Rebind("q", nil)
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ErrUnrecognized))
+	})
+
+	It("discards the rest of the current option element", func() {
+		gopt := New([]string{"program", "-ab", "-c"}, "ab")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		gopt.Rebind("bc", nil)
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(gopt.Getopt()).To(BeNil())
+	})
+
+	It("forgets state tied to the old options", func() {
+		gopt := NewLong([]string{"program", "-v", "sub"}, "+v", []Option{{Name: "verbose", Val: 'v', Required: true}})
+		gopt.Require('v')
+		parseAll(gopt)
+		Expect(gopt.Count('v')).To(Equal(1))
+
+		gopt.Rebind("v", nil)
+		Expect(gopt.Count('v')).To(Equal(0))
+		Expect(gopt.CheckRequired()).To(Succeed())
+		Expect(gopt.Ordering()).To(Equal(Permute))
+	})
+})
+
+var _ = Describe("Option aliases", func() {
+	longopts := []Option{
+		{Name: "color", Val: 'c', Aliases: []string{"colour", "col"}, Negatable: true},
+		{Name: "column", HasArg: RequiredArgument, Val: 'C'},
+	}
+
+	DescribeTable("match like the canonical name",
+		func(arg string, expected string) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":       Equal('c'),
+				"Arg":     HaveValue(Equal(expected)),
+				"LongInd": Equal(0),
+			})))
+		},
+		Entry(nil, "--colour", "true"),
+		Entry(nil, "--col", "true"),
+		Entry(nil, "--no-colour", "false"),
+		Entry(nil, "--colo", "true"),
+	)
+
+	It("are not abbreviated", func() {
+		gopt := NewLong([]string{"program", "--colou"}, "", longopts)
+		Expect(gopt.Getopt()).Error().To(MatchError(ErrUnrecognized))
+	})
+
+	It("are not ambiguous with other options", func() {
+		gopt := NewLong([]string{"program", "--colu", "x"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("C", 'C'))
+	})
+
+	It("are checked for duplicates", func() {
+		Expect(ValidateOptions(append(slices.Clone(longopts), Option{Name: "colour"}))).
+			To(MatchError(DuplicateOptionError{Name: "colour", Prefix: "--"}))
+	})
+})
+
+var _ = Describe("SetValFromName", func() {
+	var flag rune
+	longopts := []Option{
+		{Name: "xray"},
+		{Name: "yankee", Val: 'Y'},
+		{Name: "zulu", Flag: &flag, Val: 0},
+	}
+
+	DescribeTable("reports long options",
+		func(enable bool, arg string, expected rune) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			gopt.SetValFromName(enable)
+			Expect(gopt.Getopt()).To(HaveField("C", expected))
+		},
+		Entry("leaves C zero by default", false, "--xray", rune(0)),
+		Entry("uses the first letter of the name", true, "--xray", 'x'),
+		Entry("keeps an explicit Val", true, "--yankee", 'Y'),
+		Entry("keeps options with a Flag", true, "--zulu", rune(0)),
+	)
+
+	It("counts the option by its first letter", func() {
+		gopt := NewLong([]string{"program", "--xray", "--xr"}, "", longopts)
+		gopt.SetValFromName(true)
+		parseAll(gopt)
+		Expect(gopt.Count('x')).To(Equal(2))
+	})
+})
+
+var _ = Describe("Numeric options", func() {
+	It("returns numbers as options", func() {
+		gopt := New([]string{"program", "-10", "file", "-v", "-", "--", "-5"}, "v#")
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(NumberOption),
+			"Arg": HaveValue(Equal("10")),
+		})))
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("file", "-", "-5"))
+		Expect(gopt.Count(NumberOption)).To(Equal(1))
+	})
+
+	It("leaves digit clusters alone", func() {
+		gopt := New([]string{"program", "-v1"}, "v1#")
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(HaveField("C", '1'))
+	})
+
+	It("is disabled by default", func() {
+		gopt := New([]string{"program", "-10"}, "v")
+		Expect(gopt.Getopt()).Error().To(MatchError(UnrecognizedOptionError{Option: "1", Prefix: "-"}))
+	})
+
+	It("takes precedence over long-only options", func() {
+		gopt := NewLong([]string{"program", "-10"}, "#", []Option{{Name: "10x", Val: 'x'}})
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", NumberOption))
+	})
+})
+
+var _ = Describe("Empty long option arguments", func() {
+	longopts := []Option{
+		{Name: "name", HasArg: RequiredArgument, Val: 'n'},
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+		{Name: "debug", HasArg: NoArgument, Val: 'd'},
+	}
+
+	DescribeTable("are given with a trailing '='",
+		func(arg string, expected rune) {
+			gopt := NewLong([]string{"program", arg, "next"}, "", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":        Equal(expected),
+				"Arg":      HaveValue(BeEmpty()),
+				"Attached": BeTrue(),
+			})))
+			Expect(gopt.Getopt()).To(BeNil())
+			Expect(gopt.Remaining()).To(HaveExactElements("next"))
+		},
+		Entry("satisfy a required argument", "--name=", 'n'),
+		Entry("satisfy an abbreviated option", "--na=", 'n'),
+		Entry("are distinct from an absent optional argument", "--color=", 'c'),
+	)
+
+	It("leave an absent optional argument nil", func() {
+		gopt := NewLong([]string{"program", "--color"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("Arg", BeNil()))
+	})
+
+	It("are not allowed for options without arguments", func() {
+		gopt := NewLong([]string{"program", "--debug="}, "", longopts)
+		Expect(gopt.Getopt()).Error().To(MatchError(ArgumentNotAllowedError{Option: "debug", Prefix: "--"}))
+	})
+})
+
+var _ = Describe("Short and long forms with different dispositions", func() {
+	longopts := []Option{
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+	}
+
+	DescribeTable("honor their own definitions",
+		func(args []string, arg types.GomegaMatcher, remaining []string) {
+			gopt := NewLong(append([]string{"program"}, args...), "c:", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":   Equal('c'),
+				"Arg": arg,
+			})))
+			Expect(gopt.Getopt()).To(BeNil())
+			Expect(gopt.Remaining()).To(HaveExactElements(remaining))
+		},
+		Entry("short with a separate argument", []string{"-c", "x", "y"}, HaveValue(Equal("x")), []string{"y"}),
+		Entry("short with an attached argument", []string{"-cx", "y"}, HaveValue(Equal("x")), []string{"y"}),
+		Entry("long without an argument", []string{"--color", "y"}, BeNil(), []string{"y"}),
+		Entry("long with an attached argument", []string{"--color=x", "y"}, HaveValue(Equal("x")), []string{"y"}),
+		Entry("abbreviated long without an argument", []string{"--col"}, BeNil(), []string{}),
+	)
+
+	It("requires an argument for the short form", func() {
+		gopt := NewLong([]string{"program", "-c"}, "c:", longopts)
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ArgumentRequiredError{Option: "c", Prefix: "-"}))
+	})
+
+	It("applies in reverse", func() {
+		gopt := NewLong([]string{"program", "-c", "x", "--color", "y"}, "c::", []Option{
+			{Name: "color", HasArg: RequiredArgument, Val: 'c'},
+		})
+		Expect(gopt.Getopt()).To(HaveField("Arg", BeNil()))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("y"))))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+})
+
+var _ = Describe("Opt.ArgGiven", func() {
+	longopts := []Option{
+		{Name: "color", HasArg: OptionalArgument, Val: 'C'},
+		{Name: "force", Val: 'f', Negatable: true},
+	}
+
+	DescribeTable("reports whether the command line supplied an argument",
+		func(arg string, expected bool) {
+			gopt := NewLong([]string{"program", arg}, "C::o:v", longopts)
+			Expect(gopt.Getopt()).To(HaveField("ArgGiven", expected))
+		},
+		Entry(nil, "--color", false),
+		Entry(nil, "-C", false),
+		Entry(nil, "--color=always", true),
+		Entry(nil, "--color=", true),
+		Entry(nil, "-Calways", true),
+		Entry(nil, "-ofile", true),
+		Entry(nil, "-v", false),
+		Entry(nil, "--no-force", false),
+	)
+})
+
+var _ = Describe("SetAllowAbbrev", func() {
+	longopts := []Option{
+		{Name: "verbose", Val: 'v'},
+		{Name: "version", Val: 'V'},
+		{Name: "color", Val: 'c', Negatable: true},
+	}
+
+	DescribeTable("accepts full names",
+		func(arg string, expected rune) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			gopt.SetAllowAbbrev(false)
+			Expect(gopt.Getopt()).To(HaveField("C", expected))
+		},
+		Entry(nil, "--verbose", 'v'),
+		Entry(nil, "--version", 'V'),
+		Entry(nil, "--no-color", 'c'),
+	)
+
+	DescribeTable("rejects abbreviations",
+		func(arg string) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			gopt.SetAllowAbbrev(false)
+			Expect(gopt.Getopt()).Error().To(MatchError(UnrecognizedOptionError{Option: arg[2:], Prefix: "--"}))
+		},
+		Entry(nil, "--verb"),
+		Entry("that would be ambiguous", "--ver"),
+		Entry(nil, "--no-col"),
+	)
+
+	It("allows abbreviations by default", func() {
+		gopt := NewLong([]string{"program", "--verb"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+	})
+})
+
+var _ = Describe("PositionedError", func() {
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+	}
+
+	DescribeTable("reports the original index of the offending argument",
+		func(args []string, opts string, expected int) {
+			gopt := NewLong(slices.Concat([]string{"program"}, args), opts, longopts)
+			gopt.Opterr = false
+			for _, err := range gopt.All() {
+				if err != nil {
+					var positioned PositionedError
+					Expect(errors.As(err, &positioned)).To(BeTrue())
+					Expect(positioned.Index).To(Equal(expected))
+					return
+				}
+			}
+			Fail("no error")
+		},
+		Entry("unrecognized short option", []string{"-a", "-x"}, "a", 2),
+		Entry("within a cluster", []string{"-a", "-ax"}, "a", 2),
+		Entry("after permuted arguments", []string{"file", "other", "-a", "--bogus"}, "a", 4),
+		Entry("missing argument", []string{"x", "-o"}, "o:", 2),
+		Entry("separate -W argument", []string{"-W", "bogus"}, "W;", 2),
+		Entry("attached -W argument", []string{"x", "-Wbogus"}, "W;", 2),
+	)
+
+	It("keeps the message and classification", func() {
+		gopt := New([]string{"program", "-x"}, "")
+		gopt.Opterr = false
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError("unrecognized option '-x'"))
+		Expect(err).To(MatchError(ErrUnrecognized))
+		Expect(err).To(MatchError(UnrecognizedOptionError{Option: "x", Prefix: "-"}))
+	})
+})
+
+var _ = Describe("ShortOptions", func() {
+	It("lists the defined options", func() {
+		gopt := New([]string{"program"}, "ab:c::W;")
+		Expect(gopt.ShortOptions()).To(Equal(map[rune]ArgumentDisposition{
+			'a': NoArgument,
+			'b': RequiredArgument,
+			'c': OptionalArgument,
+			'W': RequiredArgument,
+		}))
+	})
+
+	It("returns a copy", func() {
+		gopt := New([]string{"program", "-x"}, "a")
+		gopt.ShortOptions()['x'] = NoArgument
+		Expect(gopt.Getopt()).Error().To(MatchError(ErrUnrecognized))
+	})
+})
+
+var _ = Describe("Ordering", func() {
+	DescribeTable("reports the ordering in effect",
+		func(opts string, setup func(*Getopt), expected Ordering) {
+			gopt := New([]string{"program"}, opts)
+			setup(gopt)
+			Expect(gopt.Ordering()).To(Equal(expected))
+		},
+		Entry("by default", "a", func(*Getopt) {}, Permute),
+		Entry("from a '+' prefix", "+a", func(*Getopt) {}, RequireOrder),
+		Entry("from a '-' prefix", "-a", func(*Getopt) {}, ReturnInOrder),
+		Entry("from SetOrdering", "a", func(g *Getopt) { _ = g.SetOrdering(ReturnInOrder) }, ReturnInOrder),
+		Entry("from StopAtFirstNonOption", "-a", func(g *Getopt) { g.StopAtFirstNonOption(true) }, RequireOrder),
+	)
+})
+
+var _ = Describe("Optional short option arguments", func() {
+	It("takes an attached argument", func() {
+		gopt := New([]string{"program", "-cvalue"}, "c::")
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": HaveValue(Equal("value")),
+		})))
+	})
+
+	DescribeTable("never takes the next argument",
+		func(opts string, expectedRemaining ...string) {
+			gopt := New([]string{"program", "-c", "value", "-d"}, opts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":   Equal('c'),
+				"Arg": BeNil(),
+			})))
+			parseAll(gopt)
+			Expect(gopt.Remaining()).To(HaveExactElements(expectedRemaining))
+		},
+		Entry("permute", "c::d", "value"),
+		Entry("require order", "+c::d", "value", "-d"),
+		Entry("return in order", "-c::d"),
+	)
+
+	It("returns the next argument in order", func() {
+		gopt := New([]string{"program", "-c", "value"}, "-c::")
+		Expect(gopt.Getopt()).To(HaveField("Arg", BeNil()))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(rune(1)),
+			"Arg": HaveValue(Equal("value")),
+		})))
+	})
+})
+
+var _ = Describe("Deprecated options", func() {
+	longopts := []Option{
+		{Name: "colour", Val: 'c', Deprecated: "use --color instead"},
+		{Name: "color", Val: 'c'},
+	}
+
+	It("warns when used", func() {
+		var warnings bytes.Buffer
+		gopt := NewLong([]string{"program", "--colour", "--color", "-W", "colour"}, "W;", longopts)
+		gopt.SetWarnWriter(&warnings)
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+		Expect(warnings.String()).To(Equal("warning: --colour is deprecated: use --color instead\n" +
+			"warning: -W colour is deprecated: use --color instead\n"))
+	})
+
+	It("is silent by default", func() {
+		gopt := NewLong([]string{"program", "--colour"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("C", 'c'))
+	})
+})
+
+var _ = Describe("AmbiguousOptionError", func() {
+	It("sorts candidates regardless of definition order", func() {
+		gopt := NewLong([]string{"program", "--o"}, "", []Option{
+			{Name: "onto", Val: '5'},
+			{Name: "one-one", Val: '3'},
+			{Name: "one", Val: '1'},
+		})
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError("option '--o' is ambiguous; possibilities: '--one' '--one-one' '--onto'"))
+		var ambig AmbiguousOptionError
+		Expect(errors.As(err, &ambig)).To(BeTrue())
+		Expect(ambig.Candidates).To(HaveExactElements("one", "one-one", "onto"))
+	})
+})
+
+var _ = Describe("NewPosix", func() {
+	It("stops at the first non-option", func() {
+		gopt := NewPosix([]string{"program", "-a", "file", "-b"}, "-ab")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("file", "-b"))
+	})
+
+	DescribeTable("rejects long options",
+		func(arg string, name string) {
+			gopt := NewPosix([]string{"program", arg, "-a"}, "a")
+			Expect(gopt.Getopt()).Error().To(MatchError(UnrecognizedOptionError{Option: name, Prefix: "--"}))
+			Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		},
+		Entry(nil, "--all", "all"),
+		Entry(nil, "--all=yes", "all=yes"),
+		Entry(nil, "--a", "a"),
+	)
+
+	It("disables the -W extension", func() {
+		gopt := NewPosix([]string{"program", "-W", "foo"}, "W;")
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('W'),
+			"Arg": BeNil(),
+		})))
+	})
+
+	It("honors the terminator", func() {
+		gopt := NewPosix([]string{"program", "--", "-a"}, "a")
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("-a"))
+	})
+})
+
+var _ = Describe("NewFromArgs", func() {
+	It("parses arguments without a program name", func() {
+		args := []string{"x", "-a", "y"}
+		gopt := NewFromArgs("program", args, "a")
+		Expect(gopt.Args[0]).To(Equal("program"))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y"))
+		Expect(args).To(HaveExactElements("x", "-a", "y"))
+	})
+
+	It("accepts an empty list", func() {
+		gopt := NewFromArgs("program", nil, "a")
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("A lone dash", func() {
+	DescribeTable("is an operand",
+		func(opts string, expected []rune, expectedRemaining ...string) {
+			gopt := New([]string{"program", "-a", "-", "-b", "-"}, opts)
+			var seen []rune
+			for opt, err := range gopt.All() {
+				Expect(err).NotTo(HaveOccurred())
+				seen = append(seen, opt.C)
+				if opt.C == 1 {
+					Expect(opt.Arg).To(HaveValue(Equal("-")))
+				}
+			}
+			Expect(seen).To(HaveExactElements(expected))
+			Expect(gopt.Remaining()).To(HaveExactElements(expectedRemaining))
+		},
+		Entry("permute", "ab", []rune{'a', 'b'}, "-", "-"),
+		Entry("require order", "+ab", []rune{'a'}, "-", "-b", "-"),
+		Entry("return in order", "-ab", []rune{'a', 1, 'b', 1}),
+	)
+
+	It("is an operand after the terminator", func() {
+		gopt := New([]string{"program", "-", "--", "-"}, "a")
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("-", "-"))
+	})
+
+	It("is not an option argument for optional arguments", func() {
+		gopt := New([]string{"program", "-c", "-"}, "c::")
+		Expect(gopt.Getopt()).To(HaveField("Arg", BeNil()))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("-"))
+	})
+
+	It("is taken as a required argument", func() {
+		gopt := New([]string{"program", "-o", "-"}, "o:")
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("-"))))
+	})
+})
+
+var _ = Describe("WOptionError", func() {
+	longopts := []Option{
+		{Name: "alpha", Val: 'a'},
+		{Name: "beta", Val: 'b'},
+	}
+
+	DescribeTable("reports a missing long option name",
+		func(args []string, expectedRemaining ...string) {
+			gopt := NewLong(slices.Concat([]string{"program"}, args), "W;", longopts)
+			_, err := gopt.Getopt()
+			Expect(err).To(MatchError(WOptionError{Prefix: "-W"}))
+			Expect(err).To(MatchError("option '-W' requires a long option name"))
+			Expect(err).To(MatchError(ErrArgumentRequired))
+			Expect(gopt.Getopt()).To(BeNil())
+			Expect(gopt.Remaining()).To(HaveExactElements(expectedRemaining))
+		},
+		Entry("at the end", []string{"-W"}),
+		Entry("before an empty argument", []string{"-W", "", "x"}, "x"),
+		Entry("before an attached value", []string{"-W=x", "y"}, "y"),
+		Entry("before a separate value", []string{"-W", "=x", "y"}, "y"),
+	)
+
+	It("uses the actual introducer", func() {
+		gopt := NewLong([]string{"program", "/W"}, "W;", longopts)
+		gopt.SetPrefixes('-', '/')
+		Expect(gopt.Getopt()).Error().To(MatchError("option '/W' requires a long option name"))
+	})
+})
+
+var _ = Describe("Operand", func() {
+	DescribeTable("returns non-option arguments by position",
+		func(opts string, i int, expected string) {
+			gopt := New([]string{"program", "src", "-v", "dst", "--", "-x"}, opts)
+			parseAll(gopt)
+			arg, ok := gopt.Operand(i)
+			Expect(ok).To(BeTrue())
+			Expect(arg).To(Equal(expected))
+		},
+		Entry(nil, "v", 0, "src"),
+		Entry(nil, "v", 1, "dst"),
+		Entry("after the terminator", "v", 2, "-x"),
+		Entry("returned in order", "-v", 1, "dst"),
+	)
+
+	DescribeTable("reports missing operands",
+		func(i int) {
+			gopt := New([]string{"program", "a", "-v"}, "v")
+			parseAll(gopt)
+			arg, ok := gopt.Operand(i)
+			Expect(ok).To(BeFalse())
+			Expect(arg).To(BeEmpty())
+		},
+		Entry("past the end", 1),
+		Entry("negative", -1),
+	)
+})
+
+var _ = Describe("Operands", func() {
+	args := []string{"program", "one", "-a", "two", "-b", "three", "--", "-c", "four"}
+
+	DescribeTable("returns operands in their original order",
+		func(opts string, preserve bool) {
+			gopt := New(slices.Clone(args), opts)
+			gopt.SetPreserveAfterTerminator(preserve)
+			parseAll(gopt)
+			Expect(gopt.Operands()).To(HaveExactElements("one", "two", "three", "-c", "four"))
+		},
+		Entry("permute", "ab", false),
+		Entry("return in order", "-ab", false),
+		Entry("preserving the tail", "ab", true),
+		Entry("return in order preserving the tail", "-ab", true),
+	)
+
+	It("includes unscanned options in require order", func() {
+		gopt := New(slices.Clone(args), "+ab")
+		parseAll(gopt)
+		Expect(gopt.Operands()).To(HaveExactElements("one", "-a", "two", "-b", "three", "--", "-c", "four"))
+	})
+
+	It("is empty without operands", func() {
+		gopt := New([]string{"program", "-a"}, "-a")
+		parseAll(gopt)
+		Expect(gopt.Operands()).To(BeEmpty())
+		Expect(gopt.Operands()).NotTo(BeNil())
+	})
+})
+
+var _ = Describe("EnableWExtension", func() {
+	longopts := []Option{
+		{Name: "alpha", HasArg: RequiredArgument, Val: 'a'},
+	}
+
+	It("reports the specification's setting", func() {
+		Expect(NewLong(nil, "W;", longopts).WEnabled()).To(BeTrue())
+		Expect(NewLong(nil, "W", longopts).WEnabled()).To(BeFalse())
+	})
+
+	It("enables the extension without W in the specification", func() {
+		gopt := NewLong([]string{"program", "-W", "alpha=x"}, "b", longopts)
+		gopt.EnableWExtension(true)
+		Expect(gopt.WEnabled()).To(BeTrue())
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('a'),
+			"Arg": HaveValue(Equal("x")),
+		})))
+	})
+
+	It("disables the extension", func() {
+		gopt := NewLong([]string{"program", "-W", "alpha=x"}, "W;", longopts)
+		gopt.EnableWExtension(false)
+		Expect(gopt.WEnabled()).To(BeFalse())
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('W'),
+			"Arg": BeNil(),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("alpha=x"))
+	})
+})
+
+var _ = Describe("SpecSyntaxError", func() {
+	It("is reported by NewStrict", func() {
+		gopt, err := NewStrict([]string{"program"}, "ab:::")
+		Expect(err).To(MatchError("invalid option specification at position 4: too many ':' after option letter"))
+		Expect(gopt).To(BeNil())
+	})
+
+	It("is ignored by New", func() {
+		gopt := New([]string{"program", "-;"}, "a;")
+		Expect(gopt.Getopt()).To(HaveField("C", ';'))
+	})
+})
+
+var _ = Describe("Option.Validate", func() {
+	errRange := errors.New("must be between 0 and 5")
+	longopts := []Option{
+		{Name: "level", HasArg: RequiredArgument, Val: 'l', Validate: func(arg string) error {
+			if n, err := strconv.Atoi(arg); err != nil || n < 0 || n > 5 {
+				return errRange
+			}
+			return nil
+		}},
+		{Name: "color", HasArg: OptionalArgument, Val: 'c', Validate: func(string) error { return errRange }},
+	}
+
+	DescribeTable("accepts valid arguments",
+		func(args ...string) {
+			gopt := NewLong(append([]string{"program"}, args...), "l:c::", longopts)
+			Expect(gopt.Getopt()).NotTo(BeNil())
+		},
+		Entry(nil, "--level=3"),
+		Entry(nil, "--level", "0"),
+		Entry(nil, "-l5"),
+		Entry("without an optional argument", "--color"),
+	)
+
+	DescribeTable("rejects invalid arguments",
+		func(arg string, expected InvalidArgumentError) {
+			gopt := NewLong([]string{"program", arg, "-l1"}, "l:c::", longopts)
+			opt, err := gopt.Getopt()
+			Expect(opt).To(BeNil())
+			Expect(err).To(MatchError(expected))
+			Expect(err).To(MatchError(ErrInvalidArgument))
+			Expect(err).To(MatchError(errRange))
+			Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("1"))))
+			Expect(gopt.Count('l')).To(Equal(1))
+		},
+		Entry(nil, "--level=7", InvalidArgumentError{Option: "level", Prefix: "--", Arg: "7", Err: errRange}),
+		Entry(nil, "-lx", InvalidArgumentError{Option: "l", Prefix: "-", Arg: "x", Err: errRange}),
+		Entry(nil, "--color=red", InvalidArgumentError{Option: "color", Prefix: "--", Arg: "red", Err: errRange}),
+	)
+})
+
+var _ = Describe("Transform", func() {
+	unquote := func(arg string) string { return strings.Trim(arg, `"'`) }
+	upper := strings.ToUpper
+
+	It("rewrites arguments before they're stored and validated", func() {
+		var checked, accumulated []string
+		longopts := []Option{
+			{Name: "name", HasArg: RequiredArgument, Val: 'n', Transform: unquote, Accumulate: &accumulated,
+				Validate: func(arg string) error {
+					checked = append(checked, arg)
+					return nil
+				}},
+		}
+		gopt := NewLong([]string{"program", `--name="x y"`, "--name", "'z'"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("x y"))))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("z"))))
+		Expect(checked).To(HaveExactElements("x y", "z"))
+		Expect(accumulated).To(HaveExactElements("x y", "z"))
+	})
+
+	It("applies registrations to short options and long options without their own", func() {
+		longopts := []Option{
+			{Name: "name", HasArg: RequiredArgument, Val: 'n'},
+			{Name: "label", HasArg: RequiredArgument, Val: 'l', Transform: upper},
+		}
+		gopt := NewLong([]string{"program", `-n"a"`, `--name="b"`, `--label="c"`}, "n:", longopts)
+		gopt.SetTransform('n', unquote)
+		gopt.SetTransform('l', unquote)
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("a"))))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("b"))))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal(`"C"`))))
+	})
+
+	It("applies an option's own function to its short form", func() {
+		longopts := []Option{
+			{Name: "level", HasArg: RequiredArgument, Val: 'l', Transform: unquote,
+				Validate: func(arg string) error {
+					_, err := strconv.Atoi(arg)
+					return err
+				}},
+		}
+		gopt := NewLong([]string{"program", "-l'3'", "--level='4'"}, "l:", longopts)
+		gopt.SetTransform('l', upper)
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("3"))))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("4"))))
+	})
+
+	It("leaves arguments supplied by the parser and in-order operands alone", func() {
+		longopts := []Option{{Name: "color", Val: 'c', Negatable: true, Transform: upper}}
+		gopt := NewLong([]string{"program", "--color", "x"}, "-", longopts)
+		gopt.SetTransform(1, upper)
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("true"))))
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("x"))))
+	})
+
+	It("can be removed", func() {
+		gopt := New([]string{"program", "-nx"}, "n:")
+		gopt.SetTransform('n', upper)
+		gopt.SetTransform('n', nil)
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("x"))))
+	})
+})
+
+var _ = Describe("SetUnknownHandler", func() {
+	longopts := []Option{
+		{Name: "verbose", Val: 'v'},
+	}
+
+	It("collects unknown options", func() {
+		var unknown []string
+		gopt := NewLong([]string{"program", "-axv", "--foo=bar", "file", "--verb", "-W", "baz"}, "avW;", longopts)
+		gopt.SetUnknownHandler(func(opt string) error {
+			unknown = append(unknown, opt)
+			return nil
+		})
+		var seen []rune
+		for opt, err := range gopt.All() {
+			Expect(err).NotTo(HaveOccurred())
+			seen = append(seen, opt.C)
+		}
+		Expect(seen).To(HaveExactElements('a', 'v', 'v'))
+		Expect(unknown).To(HaveExactElements("-x", "--foo=bar", "-W baz"))
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("returns the handler's error", func() {
+		failure := errors.New("failure")
+		gopt := New([]string{"program", "-x", "-a"}, "a")
+		gopt.SetUnknownHandler(func(string) error { return failure })
+		Expect(gopt.Getopt()).Error().To(MatchError(failure))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+	})
+
+	It("leaves other errors alone", func() {
+		gopt := New([]string{"program", "-a"}, "a:")
+		gopt.SetUnknownHandler(func(string) error { return nil })
+		Expect(gopt.Getopt()).Error().To(MatchError(ErrArgumentRequired))
+	})
+})
+
+var _ = Describe("Short option clusters", func() {
+	It("takes a required argument from the next element", func() {
+		gopt := New([]string{"program", "-abc", "value", "file"}, "abc:")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Optind()).To(Equal(1))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+		Expect(gopt.Optind()).To(Equal(1))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":        Equal('c'),
+			"Arg":      HaveValue(Equal("value")),
+			"Attached": BeFalse(),
+		})))
+		Expect(gopt.Optind()).To(Equal(3))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("takes an attached argument from the rest of the element", func() {
+		gopt := New([]string{"program", "-abcvalue", "file"}, "abc:")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":        Equal('c'),
+			"Arg":      HaveValue(Equal("value")),
+			"Attached": BeTrue(),
+		})))
+		Expect(gopt.Optind()).To(Equal(2))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("takes the rest of the element even if it holds option letters", func() {
+		gopt := New([]string{"program", "-cab", "-a"}, "abc:")
+		Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("ab"))))
+		Expect(gopt.Optind()).To(Equal(2))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(BeNil())
+	})
+
+	It("reports a missing argument at the end of the list", func() {
+		gopt := New([]string{"program", "-abc"}, "abc:")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'b'))
+		Expect(gopt.Getopt()).Error().To(MatchError(ArgumentRequiredError{Option: "c", Prefix: "-"}))
+		Expect(gopt.Optind()).To(Equal(2))
+		Expect(gopt.Getopt()).To(BeNil())
+	})
+})
+
+var _ = Describe("SetStrictRequiredArg", func() {
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+	}
+
+	DescribeTable("takes arguments that look like options by default",
+		func(args ...string) {
+			gopt := NewLong(append([]string{"program"}, args...), "o:x", longopts)
+			Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal("-x"))))
+		},
+		Entry(nil, "-o", "-x"),
+		Entry(nil, "--output", "-x"),
+	)
+
+	DescribeTable("rejects arguments that look like options",
+		func(next string, args ...string) {
+			gopt := NewLong(append([]string{"program"}, append(args, next)...), "o:x", longopts)
+			gopt.SetStrictRequiredArg(true)
+			Expect(gopt.Getopt()).Error().To(MatchError(ErrArgumentRequired))
+			Expect(gopt.Remaining()).To(HaveExactElements(next))
+		},
+		Entry(nil, "-x", "-o"),
+		Entry(nil, "-x", "--output"),
+		Entry(nil, "--output", "--output"),
+		Entry(nil, "--", "-o"),
+	)
+
+	DescribeTable("accepts other arguments",
+		func(expected string, args ...string) {
+			gopt := NewLong(append([]string{"program"}, args...), "o:x", longopts)
+			gopt.SetStrictRequiredArg(true)
+			Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal(expected))))
+		},
+		Entry(nil, "-", "-o", "-"),
+		Entry(nil, "file", "--output", "file"),
+		Entry(nil, "-x", "-o-x"),
+		Entry(nil, "-x", "--output=-x"),
+	)
+})
+
+var _ = Describe("SetPlusIntroducer", func() {
+	DescribeTable("returns plus arguments as options",
+		func(opts string, expectedRemaining ...string) {
+			gopt := New([]string{"program", "-u", "file", "+%Y-%m-%d", "+"}, opts)
+			gopt.SetPlusIntroducer('+')
+			Expect(gopt.Getopt()).To(HaveField("C", 'u'))
+			if opts[0] == '-' {
+				Expect(gopt.Getopt()).To(HaveField("C", rune(1)))
+			}
+			if opts[0] != '+' {
+				Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+					"C":   Equal(PlusOption),
+					"Arg": HaveValue(Equal("%Y-%m-%d")),
+				})))
+			}
+			parseAll(gopt)
+			Expect(gopt.Remaining()).To(HaveExactElements(expectedRemaining))
+		},
+		Entry("permute", "u", "file", "+"),
+		Entry("return in order", "-u"),
+		Entry("require order", "+u", "file", "+%Y-%m-%d", "+"),
+	)
+
+	It("is disabled by default", func() {
+		gopt := New([]string{"program", "+%Y"}, "u")
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("+%Y"))
+	})
+
+	It("leaves arguments after the terminator alone", func() {
+		gopt := New([]string{"program", "--", "+%Y"}, "u")
+		gopt.SetPlusIntroducer('+')
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("+%Y"))
+	})
+})
+
+var _ = Describe("Next", func() {
+	It("returns options by value until done", func() {
+		gopt := New([]string{"program", "-a", "-x", "-bvalue", "file"}, "ab:")
+		opt, ok, err := gopt.Next()
+		Expect(ok).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt.C).To(Equal('a'))
+
+		opt, ok, err = gopt.Next()
+		Expect(ok).To(BeTrue())
+		Expect(err).To(MatchError(ErrUnrecognized))
+		Expect(opt).To(BeZero())
+
+		opt, ok, err = gopt.Next()
+		Expect(ok).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt.C).To(Equal('b'))
+		Expect(opt.Arg).To(HaveValue(Equal("value")))
+
+		opt, ok, err = gopt.Next()
+		Expect(ok).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt).To(BeZero())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("works in a loop", func() {
+		gopt := New([]string{"program", "-a", "-b", "x"}, "ab:")
+		var seen []rune
+		for opt, ok, err := gopt.Next(); ok; opt, ok, err = gopt.Next() {
+			Expect(err).NotTo(HaveOccurred())
+			seen = append(seen, opt.C)
+		}
+		Expect(seen).To(HaveExactElements('a', 'b'))
+	})
+})
+
+var _ = Describe("NextInto", func() {
+	It("fills the caller's Opt", func() {
+		gopt := New([]string{"program", "-a", "-x", "-bvalue", "file"}, "ab:")
+		var opt Opt
+		more, err := gopt.NextInto(&opt)
+		Expect(more).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt).To(BeComparableTo(Opt{C: 'a', LongInd: -1}))
+
+		more, err = gopt.NextInto(&opt)
+		Expect(more).To(BeTrue())
+		Expect(err).To(MatchError(ErrUnrecognized))
+		Expect(opt).To(BeZero())
+
+		more, err = gopt.NextInto(&opt)
+		Expect(more).To(BeTrue())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt).To(BeComparableTo(Opt{C: 'b', Arg: ptr("value"), LongInd: -1}))
+
+		more, err = gopt.NextInto(&opt)
+		Expect(more).To(BeFalse())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt).To(BeZero())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("clears fields left from the previous option", func() {
+		gopt := NewLong([]string{"program", "--name=x", "-a"}, "a", []Option{{Name: "name", HasArg: RequiredArgument}})
+		var opt Opt
+		_, _ = gopt.NextInto(&opt)
+		Expect(opt.Long).NotTo(BeNil())
+		_, _ = gopt.NextInto(&opt)
+		Expect(opt).To(Equal(Opt{C: 'a', LongInd: -1, Repeat: 1}))
+	})
+})
+
+var _ = Describe("Greedy options", func() {
+	longopts := []Option{
+		{Name: "exec", Val: 'e', Greedy: true},
+		{Name: "shell", HasArg: RequiredArgument, Val: 's', Greedy: true},
+	}
+
+	It("collects everything after the option", func() {
+		gopt := NewLong([]string{"program", "file", "-v", "--exec", "ls", "-l", "--", "x"}, "ve", longopts)
+		Expect(gopt.Getopt()).To(HaveField("C", 'v'))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":    Equal('e'),
+			"Args": HaveExactElements("ls", "-l", "--", "x"),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+		Expect(gopt.TerminatorIndex()).To(Equal(-1))
+	})
+
+	It("takes its own argument first", func() {
+		gopt := NewLong([]string{"program", "--shell", "sh", "-c", "echo"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":    Equal('s'),
+			"Arg":  HaveValue(Equal("sh")),
+			"Args": HaveExactElements("-c", "echo"),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(BeEmpty())
+	})
+
+	It("collects nothing at the end", func() {
+		gopt := NewLong([]string{"program", "--exec"}, "", longopts)
+		Expect(gopt.Getopt()).To(HaveField("Args", BeEmpty()))
+	})
+
+	It("does not apply to the short option", func() {
+		gopt := NewLong([]string{"program", "-e", "ls"}, "e", longopts)
+		Expect(gopt.Getopt()).To(HaveField("Args", BeNil()))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("ls"))
+	})
+})
+
+var _ = Describe("ReturnInOrder with clusters", func() {
+	DescribeTable("returns operands between the options around them",
+		func(args []string, expected []string, operands []string) {
+			gopt := New(append([]string{"program"}, args...), "-abcd:")
+			var result []string
+			for opt, err := range gopt.All() {
+				Expect(err).NotTo(HaveOccurred())
+				switch {
+				case opt.C == 1:
+					result = append(result, *opt.Arg)
+				case opt.Arg != nil:
+					result = append(result, string(opt.C)+"="+*opt.Arg)
+				default:
+					result = append(result, string(opt.C))
+				}
+			}
+			Expect(result).To(HaveExactElements(expected))
+			Expect(gopt.Remaining()).To(BeEmpty())
+			Expect(gopt.Operands()).To(HaveExactElements(operands))
+		},
+		Entry(nil, []string{"-a", "x", "-b"}, []string{"a", "x", "b"}, []string{"x"}),
+		Entry(nil, []string{"-ab", "x", "-c"}, []string{"a", "b", "x", "c"}, []string{"x"}),
+		Entry(nil, []string{"-ab", "x", "y", "-bc", "z"}, []string{"a", "b", "x", "y", "b", "c", "z"},
+			[]string{"x", "y", "z"}),
+		Entry(nil, []string{"x", "-ab", "y"}, []string{"x", "a", "b", "y"}, []string{"x", "y"}),
+		Entry(nil, []string{"-ad", "x", "y", "-c"}, []string{"a", "d=x", "y", "c"}, []string{"y"}),
+		Entry(nil, []string{"-adx", "y", "-c"}, []string{"a", "d=x", "y", "c"}, []string{"y"}),
+		Entry(nil, []string{"-ab", "-", "-c"}, []string{"a", "b", "-", "c"}, []string{"-"}),
+	)
+})
+
+var _ = Describe("SetInOrderRune", func() {
+	var flag rune
+	longopts := []Option{
+		{Name: "long", Val: 'L'},
+		{Name: "flag", Flag: &flag, Val: 'f'},
+	}
+
+	It("marks operands with the chosen rune", func() {
+		gopt := NewLong([]string{"program", "x", "-a", "y", "--long"}, "-a", longopts)
+		Expect(gopt.SetInOrderRune('@')).To(Succeed())
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('@'),
+			"Arg": HaveValue(Equal("x")),
+		})))
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveField("C", '@'))
+		Expect(gopt.Getopt()).To(HaveField("C", 'L'))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Operands()).To(HaveExactElements("x", "y"))
+		Expect(gopt.Count('@')).To(Equal(2))
+	})
+
+	It("lets a short option use the default marker", func() {
+		gopt := New([]string{"program", "x", "-\x01"}, "-\x01")
+		Expect(gopt.SetInOrderRune(InOrderOption)).To(MatchError(InOrderRuneError{Rune: InOrderOption}))
+		Expect(gopt.SetInOrderRune(-1)).To(Succeed())
+		Expect(gopt.Getopt()).To(HaveField("C", rune(-1)))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(InOrderOption),
+			"Arg": BeNil(),
+		})))
+		Expect(gopt.Operands()).To(HaveExactElements("x"))
+	})
+
+	DescribeTable("rejects runes that could be options",
+		func(r rune) {
+			gopt := NewLong([]string{"program", "x"}, "-a", longopts)
+			Expect(gopt.SetInOrderRune(r)).To(MatchError(InOrderRuneError{Rune: r}))
+			Expect(gopt.Getopt()).To(HaveField("C", InOrderOption))
+		},
+		Entry("short option", 'a'),
+		Entry("long option value", 'L'),
+		Entry("flag options", rune(0)),
+		Entry(nil, PlusOption),
+		Entry(nil, NumberOption),
+	)
+
+	It("allows the value of a long option that sets a flag", func() {
+		gopt := NewLong([]string{"program"}, "-a", longopts)
+		Expect(gopt.SetInOrderRune('f')).To(Succeed())
+	})
+})
+
+var _ = Describe("SetAutoHelp and SetAutoVersion", func() {
+	// parse sets up a parser with automatic options and returns the result of the first call to GetoptLong and the
+	// output.
+	parse := func(argv []string, longopts []Option) (*Opt, string, error) {
+		var out strings.Builder
+		gopt := NewLong(append([]string{"program"}, argv...), "ab", longopts)
+		gopt.Out = &out
+		gopt.Opterr = true
+		gopt.SetAutoHelp("usage: program [-ab]")
+		gopt.SetAutoVersion("program 1.0\n")
+		opt, err := gopt.GetoptLong()
+		return opt, out.String(), err
+	}
+
+	DescribeTable("handle the options",
+		func(arg string, expected error, output string) {
+			opt, out, err := parse([]string{arg}, nil)
+			Expect(opt).To(BeNil())
+			Expect(err).To(MatchError(expected))
+			Expect(out).To(Equal(output))
+		},
+		Entry(nil, "--help", ErrHelpRequested, "usage: program [-ab]\n"),
+		Entry(nil, "--h", ErrHelpRequested, "usage: program [-ab]\n"),
+		Entry(nil, "--version", ErrVersionRequested, "program 1.0\n"),
+		Entry(nil, "--ver", ErrVersionRequested, "program 1.0\n"),
+		Entry("with an argument", "--help=x", ErrArgumentNotAllowed, "option '--help' doesn't allow an argument\n"),
+	)
+
+	It("defers to a defined option", func() {
+		opt, out, err := parse([]string{"--help"}, []Option{{Name: "help", Val: 'h'}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opt).To(HaveField("C", 'h'))
+		Expect(out).To(BeEmpty())
+	})
+
+	It("treats an abbreviation that matches a defined option as ambiguous", func() {
+		_, _, err := parse([]string{"--he"}, []Option{{Name: "hex", Val: 'x'}})
+		Expect(err).To(MatchError(AmbiguousOptionError{
+			Option:     "he",
+			Prefix:     "--",
+			Candidates: []string{"help", "hex"},
+		}))
+		_, _, err = parse([]string{"--help"}, []Option{{Name: "hex", Val: 'x'}})
+		Expect(err).To(MatchError(ErrHelpRequested))
+	})
+
+	It("works with long-only parsing", func() {
+		gopt := New([]string{"program", "-version"}, "v")
+		var out strings.Builder
+		gopt.Out = &out
+		gopt.SetAutoVersion("1.0")
+		_, err := gopt.GetoptLongOnly()
+		Expect(err).To(MatchError(ErrVersionRequested))
+		Expect(out.String()).To(Equal("1.0\n"))
+	})
+
+	It("is off by default", func() {
+		gopt := New([]string{"program", "--help"}, "h")
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ErrUnrecognized))
+	})
+
+	It("can be turned off", func() {
+		gopt := New([]string{"program", "--help"}, "h")
+		gopt.SetAutoHelp("usage")
+		gopt.SetAutoHelp("")
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ErrUnrecognized))
+	})
+})
+
+func ExampleGetopt_SetAutoHelp() {
+	gopt := New([]string{"program", "-v", "--help"}, "v")
+	gopt.Out = os.Stdout
+	gopt.SetAutoHelp("usage: program [-v]")
+	var err error
+	for opt, optErr := range gopt.All() {
+		if optErr != nil {
+			err = optErr
+			break
+		}
+		_, _ = fmt.Printf("-%c\n", opt.C)
+	}
+	// HandleError prints nothing for the help request and reports success.
+	_, _ = fmt.Println("exit status", HandleError(os.Stdout, "program", err))
+	// Output:
+	// -v
+	// usage: program [-v]
+	// exit status 0
+}
+
+var _ = DescribeTable("ArgumentDisposition.String",
+	func(d ArgumentDisposition, expected string) {
+		Expect(d.String()).To(Equal(expected))
+		Expect(fmt.Sprint(d)).To(Equal(expected))
+	},
+	Entry(nil, NoArgument, "NoArgument"),
+	Entry(nil, RequiredArgument, "RequiredArgument"),
+	Entry(nil, OptionalArgument, "OptionalArgument"),
+	Entry(nil, ArgumentDisposition(7), "ArgumentDisposition(7)"),
+)
+
+var _ = DescribeTable("Ordering.String",
+	func(o Ordering, expected string) {
+		Expect(o.String()).To(Equal(expected))
+		Expect(fmt.Sprintf("%v", o)).To(Equal(expected))
+	},
+	Entry(nil, RequireOrder, "RequireOrder"),
+	Entry(nil, Permute, "Permute"),
+	Entry(nil, ReturnInOrder, "ReturnInOrder"),
+	Entry(nil, Ordering(-1), "Ordering(-1)"),
+)
+
+var _ = Describe("GetoptLongOnly", func() {
+	longopts := []Option{
+		{Name: "foo", HasArg: RequiredArgument, Val: 'F'},
+		{Name: "verbose", HasArg: NoArgument, Val: 'V'},
+	}
+
+	DescribeTable("splits an attached argument after a single dash",
+		func(arg string) {
+			gopt := NewLong([]string{"program", arg}, "fv", longopts)
+			Expect(gopt.GetoptLongOnly()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":        Equal('F'),
+				"Arg":      HaveValue(Equal("bar")),
+				"LongInd":  Equal(0),
+				"Attached": BeTrue(),
+			})))
+		},
+		Entry(nil, "-foo=bar"),
+		Entry("abbreviated", "-fo=bar"),
+		Entry("with two dashes", "--foo=bar"),
+	)
+
+	It("takes a separate argument after a single dash", func() {
+		gopt := NewLong([]string{"program", "-foo", "bar"}, "", longopts)
+		Expect(gopt.GetoptLongOnly()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('F'),
+			"Arg": HaveValue(Equal("bar")),
+		})))
+	})
+
+	DescribeTable("reports errors with the prefix that was used",
+		func(arg string, expected error, message string) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			opt, err := gopt.GetoptLongOnly()
+			Expect(opt).To(BeNil())
+			Expect(err).To(MatchError(expected))
+			Expect(err).To(MatchError(message))
+		},
+		Entry(nil, "-foo", ArgumentRequiredError{Option: "foo", Prefix: "-"},
+			"option '-foo' requires an argument"),
+		Entry(nil, "-verbose=x", ArgumentNotAllowedError{Option: "verbose", Prefix: "-"},
+			"option '-verbose' doesn't allow an argument"),
+		Entry(nil, "-bogus=x", UnrecognizedOptionError{Option: "bogus=x", Prefix: "-"},
+			"unrecognized option '-bogus=x'"),
+		Entry(nil, "--verbose=x", ArgumentNotAllowedError{Option: "verbose", Prefix: "--"},
+			"option '--verbose' doesn't allow an argument"),
+	)
+
+	It("treats a lone short option letter as the short option", func() {
+		gopt := NewLong([]string{"program", "-f", "-v"}, "fv", longopts)
+		Expect(gopt.GetoptLongOnly()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":       Equal('f'),
+			"LongInd": Equal(-1),
+		})))
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", 'v'))
+	})
+
+	It("falls back to short options for a cluster that isn't a long option", func() {
+		gopt := NewLong([]string{"program", "-vf"}, "fv", longopts)
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", 'v'))
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", 'f'))
+	})
+})
+
+var _ = Describe("Argument errors for abbreviated long options", func() {
+	longopts := []Option{
+		{Name: "sample", Val: 's', Aliases: []string{"smp"}},
+		{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+		{Name: "color", Val: 'c', Negatable: true},
+	}
+
+	DescribeTable("report the full name",
+		func(arg string, expected error, message string) {
+			gopt := NewLong([]string{"program", arg}, "W;", longopts)
+			_, err := gopt.Getopt()
+			Expect(err).To(MatchError(expected))
+			Expect(err).To(MatchError(message))
+		},
+		Entry(nil, "--sam=x", ArgumentNotAllowedError{Option: "sample", Prefix: "--"},
+			"option '--sample' doesn't allow an argument"),
+		Entry(nil, "--out", ArgumentRequiredError{Option: "output", Prefix: "--"},
+			"option '--output' requires an argument"),
+		Entry(nil, "-Wsam=x", ArgumentNotAllowedError{Option: "sample", Prefix: "-W "},
+			"option '-W sample' doesn't allow an argument"),
+		Entry("as typed for an alias", "--smp=x", ArgumentNotAllowedError{Option: "smp", Prefix: "--"},
+			"option '--smp' doesn't allow an argument"),
+		Entry("with the negation prefix", "--no-col=x", ArgumentNotAllowedError{Option: "no-color", Prefix: "--"},
+			"option '--no-color' doesn't allow an argument"),
+	)
+
+	It("reports the full name with long-only parsing", func() {
+		gopt := NewLong([]string{"program", "-sam=x"}, "", longopts)
+		_, err := gopt.GetoptLongOnly()
+		Expect(err).To(MatchError(ArgumentNotAllowedError{Option: "sample", Prefix: "-"}))
+	})
+})
+
+var _ = Describe("Option.SeparateArg", func() {
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o', SeparateArg: true},
+		{Name: "name", HasArg: RequiredArgument, Val: 'n'},
+	}
+
+	DescribeTable("accepts a separate argument",
+		func(argv ...string) {
+			gopt := NewLong(append([]string{"program"}, argv...), "o:n:v", longopts)
+			Expect(gopt.GetoptLong()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":        Equal('o'),
+				"Arg":      HaveValue(Equal("file")),
+				"Attached": BeFalse(),
+			})))
+		},
+		Entry("short", "-o", "file"),
+		Entry("long", "--output", "file"),
+		Entry("abbreviated long", "--out", "file"),
+	)
+
+	DescribeTable("rejects an attached argument",
+		func(arg string, expected AttachedArgumentError) {
+			gopt := NewLong([]string{"program", arg, "-v"}, "o:n:v", longopts)
+			opt, err := gopt.GetoptLong()
+			Expect(opt).To(BeNil())
+			Expect(err).To(MatchError(expected))
+			Expect(err).To(MatchError(ErrAttachedArgument))
+			Expect(err).To(MatchError(fmt.Sprintf("option '%s%s' requires its argument as a separate word",
+				expected.Prefix, expected.Option)))
+			Expect(gopt.GetoptLong()).To(HaveField("C", 'v'))
+		},
+		Entry("short", "-ovalue", AttachedArgumentError{Option: "o", Prefix: "-", Arg: "value"}),
+		Entry("long", "--output=value", AttachedArgumentError{Option: "output", Prefix: "--", Arg: "value"}),
+		Entry("long with an empty argument", "--output=", AttachedArgumentError{Option: "output", Prefix: "--"}),
+	)
+
+	It("checks an option in a cluster", func() {
+		gopt := NewLong([]string{"program", "-vo", "file", "-vovalue"}, "o:n:v", longopts)
+		Expect(gopt.GetoptLong()).To(HaveField("C", 'v'))
+		Expect(gopt.GetoptLong()).To(HaveField("Arg", HaveValue(Equal("file"))))
+		Expect(gopt.GetoptLong()).To(HaveField("C", 'v'))
+		_, err := gopt.GetoptLong()
+		Expect(err).To(MatchError(AttachedArgumentError{Option: "o", Prefix: "-", Arg: "value"}))
+	})
+
+	It("leaves other options alone", func() {
+		gopt := NewLong([]string{"program", "-nvalue", "--name=other"}, "o:n:v", longopts)
+		Expect(gopt.GetoptLong()).To(HaveField("Arg", HaveValue(Equal("value"))))
+		Expect(gopt.GetoptLong()).To(HaveField("Arg", HaveValue(Equal("other"))))
+	})
+
+	It("doesn't apply to a short option with a different long option", func() {
+		gopt := NewLong([]string{"program", "-ofile"}, "o:", []Option{
+			{Name: "output", HasArg: RequiredArgument, Val: 'x', SeparateArg: true},
+		})
+		Expect(gopt.GetoptLong()).To(HaveField("Arg", HaveValue(Equal("file"))))
+	})
+})
+
+var _ = Describe("Non-ASCII options", func() {
+	It("splits a cluster of multibyte option letters", func() {
+		gopt := New([]string{"program", "-éλxyz", "-ß"}, "éλ:ß")
+		Expect(gopt.Getopt()).To(HaveField("C", 'é'))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('λ'),
+			"Arg": HaveValue(Equal("xyz")),
+		})))
+		Expect(gopt.Getopt()).To(HaveField("C", 'ß'))
+		Expect(gopt.Getopt()).To(BeNil())
+	})
+
+	It("reports a multibyte unrecognized option", func() {
+		gopt := New([]string{"program", "-aπ"}, "a")
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(UnrecognizedOptionError{Option: "π", Prefix: "-"}))
+	})
+
+	It("collapses repeated multibyte flags", func() {
+		gopt := New([]string{"program", "-ééé"}, "é")
+		gopt.SetCollapseRepeats(true)
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":      Equal('é'),
+			"Repeat": Equal(3),
+		})))
+	})
+
+	It("uses a multibyte introducer and separator", func() {
+		gopt := NewLong([]string{"program", "−é", "−−name→x"}, "é", []Option{
+			{Name: "name", HasArg: RequiredArgument, Val: 'n'},
+		})
+		gopt.SetPrefixes('−')
+		gopt.SetLongSeparator('→')
+		Expect(gopt.GetoptLong()).To(HaveField("C", 'é'))
+		Expect(gopt.GetoptLong()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('n'),
+			"Arg": HaveValue(Equal("x")),
+		})))
+	})
+
+	It("distinguishes a lone multibyte short option from a long option with long-only parsing", func() {
+		gopt := NewLong([]string{"program", "-é", "-él"}, "é", []Option{{Name: "élan", Val: 'E'}})
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", 'é'))
+		Expect(gopt.GetoptLongOnly()).To(HaveField("C", 'E'))
+	})
+})
+
+// ptr returns a pointer to a copy of s, for building expected Opt values.
+func ptr(s string) *string {
+	return &s
+}
+
+var _ = Describe("Opt.Equal", func() {
+	DescribeTable("compares options and their arguments",
+		func(a, b Opt, expected bool) {
+			Expect(a.Equal(b)).To(Equal(expected))
+			Expect(b.Equal(a)).To(Equal(expected))
+		},
+		Entry("without arguments", Opt{C: 'a', LongInd: -1}, Opt{C: 'a', LongInd: -1}, true),
+		Entry("with equal arguments in different variables",
+			Opt{C: 'b', Arg: ptr("x"), LongInd: -1}, Opt{C: 'b', Arg: ptr("x"), LongInd: -1}, true),
+		Entry("with different arguments",
+			Opt{C: 'b', Arg: ptr("x"), LongInd: -1}, Opt{C: 'b', Arg: ptr("y"), LongInd: -1}, false),
+		Entry("with one argument missing", Opt{C: 'b', Arg: ptr(""), LongInd: -1}, Opt{C: 'b', LongInd: -1}, false),
+		Entry("with different characters", Opt{C: 'a', LongInd: -1}, Opt{C: 'b', LongInd: -1}, false),
+		Entry("with different long options", Opt{C: 'a', LongInd: 0}, Opt{C: 'a', LongInd: 1}, false),
+		Entry("ignoring other fields", Opt{C: 'a', Repeat: 1, Attached: true}, Opt{C: 'a'}, true),
+	)
+
+	It("supports BeComparableTo", func() {
+		gopt := New([]string{"program", "-ax", "-b", "y"}, "ab:x")
+		Expect(gopt.Snapshot()).To(BeComparableTo([]Opt{
+			{C: 'a', LongInd: -1},
+			{C: 'x', LongInd: -1},
+			{C: 'b', Arg: ptr("y"), LongInd: -1},
+		}))
+	})
+})
+
+var _ = Describe("SetLongSeparator", func() {
+	longopts := []Option{
+		{Name: "define", HasArg: RequiredArgument, Val: 'D'},
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+		{Name: "verbose", HasArg: NoArgument, Val: 'v'},
+	}
+
+	DescribeTable("splits the argument at the separator",
+		func(args []string, expected Opt) {
+			gopt := NewLong(append([]string{"program"}, args...), "W;", longopts)
+			gopt.SetLongSeparator(':')
+			opt, err := gopt.Getopt()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*opt).To(BeComparableTo(expected))
+		},
+		Entry(nil, []string{"--define:x=1"}, Opt{C: 'D', Arg: ptr("x=1"), LongInd: 0}),
+		Entry("abbreviated", []string{"--def:x"}, Opt{C: 'D', Arg: ptr("x"), LongInd: 0}),
+		Entry("empty", []string{"--define:"}, Opt{C: 'D', Arg: ptr(""), LongInd: 0}),
+		Entry("separate", []string{"--define", "x=1"}, Opt{C: 'D', Arg: ptr("x=1"), LongInd: 0}),
+		Entry("optional", []string{"--color:always"}, Opt{C: 'c', Arg: ptr("always"), LongInd: 1}),
+		Entry("with -W", []string{"-W", "define:y"}, Opt{C: 'D', Arg: ptr("y"), LongInd: 0}),
+	)
+
+	DescribeTable("treats '=' as part of the name",
+		func(arg string, expected error) {
+			gopt := NewLong([]string{"program", arg}, "", longopts)
+			gopt.SetLongSeparator(':')
+			_, err := gopt.Getopt()
+			Expect(err).To(MatchError(expected))
+		},
+		Entry(nil, "--define=x", UnrecognizedOptionError{Option: "define=x", Prefix: "--"}),
+		Entry(nil, "--color=always", UnrecognizedOptionError{Option: "color=always", Prefix: "--"}),
+	)
+
+	It("rejects an argument for an option that takes none", func() {
+		gopt := NewLong([]string{"program", "--verb:x", "--verbose=x"}, "", longopts)
+		gopt.SetLongSeparator(':')
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ArgumentNotAllowedError{Option: "verbose", Prefix: "--"}))
+		_, err = gopt.Getopt()
+		Expect(err).To(MatchError(UnrecognizedOptionError{Option: "verbose=x", Prefix: "--"}))
+	})
+
+	It("reports an ambiguous name without the argument", func() {
+		gopt := NewLong([]string{"program", "--c:x"}, "", []Option{{Name: "cat", Val: 'a'}, {Name: "cow", Val: 'b'}})
+		gopt.SetLongSeparator(':')
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(ErrAmbiguous))
+	})
+})
+
+var _ = Describe("Option.NArgs", func() {
+	longopts := []Option{
+		{Name: "point", HasArg: RequiredArgument, Val: 'p', NArgs: 2},
+		{Name: "name", HasArg: RequiredArgument, Val: 'n'},
+	}
+
+	DescribeTable("collects the arguments",
+		func(args ...string) {
+			gopt := NewLong(append([]string{"program", "a"}, append(args, "b")...), "p:n:", longopts)
+			Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+				"C":    Equal('p'),
+				"Arg":  HaveValue(Equal("1")),
+				"Args": HaveExactElements("1", "-2"),
+			})))
+			Expect(gopt.Getopt()).To(BeNil())
+			Expect(gopt.Remaining()).To(HaveExactElements("a", "b"))
+		},
+		Entry(nil, "--point", "1", "-2"),
+		Entry("with the first attached", "--point=1", "-2"),
+		Entry("as a short option", "-p", "1", "-2"),
+		Entry("as a short option with the first attached", "-p1", "-2"),
+	)
+
+	It("leaves other options alone", func() {
+		gopt := NewLong([]string{"program", "-n", "x", "y"}, "p:n:", longopts)
+		Expect(gopt.Getopt()).To(HaveField("Args", BeNil()))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("y"))
+	})
+
+	DescribeTable("requires every argument",
+		func(arg string, expected ArgumentRequiredError) {
+			gopt := NewLong([]string{"program", arg, "1"}, "p:n:", longopts)
+			opt, err := gopt.Getopt()
+			Expect(opt).To(BeNil())
+			Expect(err).To(MatchError(expected))
+			Expect(gopt.Getopt()).To(BeNil())
+		},
+		Entry(nil, "--point", ArgumentRequiredError{Option: "point", Prefix: "--"}),
+		Entry(nil, "-p", ArgumentRequiredError{Option: "p", Prefix: "-"}),
+	)
+
+	It("transforms and validates each argument", func() {
+		errNegative := errors.New("negative")
+		gopt := NewLong([]string{"program", "--point", " 1", " -2 "}, "", []Option{{
+			Name: "point", HasArg: RequiredArgument, Val: 'p', NArgs: 2,
+			Transform: strings.TrimSpace,
+			Validate: func(arg string) error {
+				if strings.HasPrefix(arg, "-") {
+					return errNegative
+				}
+				return nil
+			},
+		}})
+		_, err := gopt.Getopt()
+		Expect(err).To(MatchError(InvalidArgumentError{Option: "point", Prefix: "--", Arg: "-2", Err: errNegative}))
 	})
 })
@@ -13,6 +13,7 @@ func optFields(ordering, w, opts types.GomegaMatcher) Fields {
 	return Fields{
 		"Ordering": ordering,
 		"W":        w,
+		"Numeric":  BeFalse(),
 		"Opts":     opts,
 	}
 }
@@ -75,9 +76,11 @@ var _ = Describe("Option parsing", func() {
 		)
 	})
 
+	// Some of these specifications are malformed; they're still interpreted as well as possible.
 	DescribeTable("handles W options",
 		func(opts string, fields Fields) {
-			Expect(parseShortOptionSpec(opts)).To(MatchAllFields(fields))
+			info, _ := parseShortOptionSpec(opts)
+			Expect(info).To(MatchAllFields(fields))
 		},
 		Entry(nil, "W;", optFields(Ignore(), BeTrue(), MatchAllKeys(Keys{
 			'W': Equal(NoArgument),
@@ -101,4 +104,58 @@ var _ = Describe("Option parsing", func() {
 			'W': Equal(OptionalArgument),
 		}))),
 	)
+
+	DescribeTable("detects duplicate letters",
+		func(opts string, duplicate string) {
+			_, err := parseShortOptionSpec(opts)
+			Expect(err).To(MatchError(DuplicateOptionError{Name: duplicate, Prefix: "-"}))
+		},
+		Entry(nil, "abca", "a"),
+		Entry(nil, "a:ba::", "a"),
+		Entry(nil, "+xW;yW", "W"),
+		Entry(nil, ":x:y:x", "x"),
+	)
+
+	DescribeTable("detects syntax errors",
+		func(opts string, pos int, message string) {
+			_, err := parseShortOptionSpec(opts)
+			Expect(err).To(MatchError(SpecSyntaxError{Pos: pos, Message: message}))
+		},
+		Entry(nil, "a:::", 3, "too many ':' after option letter"),
+		Entry(nil, "+b::::", 4, "too many ':' after option letter"),
+		Entry(nil, ";W", 0, "';' does not follow 'W'"),
+		Entry(nil, "w;", 1, "';' does not follow 'W'"),
+		Entry(nil, "a:;", 2, "';' does not follow 'W'"),
+		Entry(nil, "W;:", 2, "':' does not follow an option letter"),
+		Entry(nil, "-::a", 2, "':' does not follow an option letter"),
+		Entry(nil, "#:", 1, "':' does not follow an option letter"),
+	)
+
+	It("reports the first problem", func() {
+		_, err := parseShortOptionSpec("aa:::")
+		Expect(err).To(MatchError(DuplicateOptionError{Name: "a", Prefix: "-"}))
+	})
+
+	DescribeTable("accepts distinct letters",
+		func(opts string) {
+			_, err := parseShortOptionSpec(opts)
+			Expect(err).NotTo(HaveOccurred())
+		},
+		Entry(nil, "abc"),
+		Entry(nil, "-a:b::c"),
+		Entry(nil, ":W;"),
+		Entry(nil, "+:a::b:#W;"),
+	)
+
+	It("enables numeric options", func() {
+		Expect(parseShortOptionSpec("a#b:")).To(MatchAllFields(Fields{
+			"Ordering": Equal(Permute),
+			"W":        BeFalse(),
+			"Numeric":  BeTrue(),
+			"Opts": MatchAllKeys(Keys{
+				'a': Equal(NoArgument),
+				'b': Equal(RequiredArgument),
+			}),
+		}))
+	})
 })
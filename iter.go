@@ -1,53 +1,157 @@
 package getopt
 
 import (
+	"context"
+	"errors"
 	"iter"
+	"slices"
 )
 
-// Iterate returns an iterator for options parsed from the given argument list. When iteration terminates, the slice
-// pointer, if non-nil, will hold the remaining unparsed arguments.
-func Iterate(args []string, opts string, remaining *[]string) iter.Seq2[*Opt, error] {
-	g := New(args, opts)
+// detach returns a copy of opt whose Arg, if any, points to its own copy of the argument. Arg may point into Args,
+// which permutation can rearrange later in the scan, so results that outlive the next call to the parser need this.
+func detach(opt *Opt) Opt {
+	o := *opt
+	if o.Arg != nil {
+		arg := *o.Arg
+		o.Arg = &arg
+	}
+	return o
+}
+
+// unconsumedFrom returns the non-option arguments that scanning has skipped but not yet permuted, followed by the
+// elements of Args from index start onward. Those are all the arguments not yet consumed when scanning stops early.
+func (g *Getopt) unconsumedFrom(start int) []string {
+	if g.firstNonopt == g.lastNonopt {
+		return g.remainingFrom(start)
+	}
+	return slices.Concat(g.Args[g.firstNonopt:g.lastNonopt], g.remainingFrom(start))
+}
+
+// iterate returns an iterator that yields the results of next until it reports neither an option nor an error. When
+// iteration terminates, the slice pointer, if non-nil, will hold g's remaining unparsed arguments. If the caller stops
+// iterating early, those are the non-option arguments skipped so far followed by the unscanned arguments, which begin
+// with the argument that caused the error if the caller stopped right after one.
+func iterate(g *Getopt, next func() (*Opt, error), remaining *[]string) iter.Seq2[*Opt, error] {
 	return func(yield func(*Opt, error) bool) {
-		for opt, err := g.Getopt(); opt != nil || err != nil; opt, err = g.Getopt() {
+		stopped, failed := false, false
+		for opt, err := next(); opt != nil || err != nil; opt, err = next() {
 			if !yield(opt, err) {
+				stopped, failed = true, err != nil
 				break
 			}
 		}
 		if remaining != nil {
-			*remaining = g.Args[g.Optind():]
+			switch {
+			case !stopped:
+				*remaining = g.Remaining()
+			case failed:
+				// The element holding the error hasn't been permuted, so it's still at its original index.
+				*remaining = g.unconsumedFrom(g.current)
+			default:
+				*remaining = g.unconsumedFrom(g.optind)
+			}
 		}
 	}
 }
 
+// Iterate returns an iterator for options parsed from the given argument list. When iteration terminates, the slice
+// pointer, if non-nil, will hold the remaining unparsed arguments. That's so even when the loop ends with break: the
+// slice holds every argument not yet consumed. If the loop breaks right after an error, the slice begins with the
+// whole argument that caused the error, even if part of it, such as "-a" in "-ax", was already consumed. Non-option
+// arguments skipped before that point come first, ahead of the arguments that haven't been scanned yet.
+func Iterate(args []string, opts string, remaining *[]string) iter.Seq2[*Opt, error] {
+	g := New(args, opts)
+	return iterate(g, g.Getopt, remaining)
+}
+
 // IterateLong returns an iterator for options parsed from the given argument list and option definitions. When
 // iteration terminates, the slice pointer, if non-nil, will hold the remaining unparsed arguments.
 func IterateLong(args []string, opts string, longOptions []Option, remaining *[]string) iter.Seq2[*Opt, error] {
 	g := NewLong(args, opts, longOptions)
-	return func(yield func(*Opt, error) bool) {
-		for opt, err := g.Getopt(); opt != nil || err != nil; opt, err = g.Getopt() {
-			if !yield(opt, err) {
-				break
-			}
-		}
-		if remaining != nil {
-			*remaining = g.Args[g.Optind():]
-		}
-	}
+	return iterate(g, g.Getopt, remaining)
 }
 
 // IterateLongOnly returns an iterator for options parsed from the given argument list and option definitions. When
 // iteration terminates, the slice pointer, if non-nil, will hold the remaining unparsed arguments.
 func IterateLongOnly(args []string, opts string, longOptions []Option, remaining *[]string) iter.Seq2[*Opt, error] {
 	g := NewLong(args, opts, longOptions)
+	return iterate(g, g.GetoptLongOnly, remaining)
+}
+
+// IterateContext is like [Iterate], but it stops when ctx is done. Before yielding each result, it checks ctx, and if
+// ctx has been canceled or its deadline has passed, it yields a final nil option with ctx.Err() instead and ends the
+// iteration. The result that was about to be yielded is dropped, and remaining, if non-nil, holds the arguments after
+// it.
+func IterateContext(ctx context.Context, args []string, opts string, remaining *[]string) iter.Seq2[*Opt, error] {
+	g := New(args, opts)
 	return func(yield func(*Opt, error) bool) {
-		for opt, err := g.GetoptLongOnly(); opt != nil || err != nil; opt, err = g.GetoptLongOnly() {
+		for opt, err := range iterate(g, g.Getopt, remaining) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				yield(nil, ctxErr)
+				break
+			}
 			if !yield(opt, err) {
 				break
 			}
 		}
-		if remaining != nil {
-			*remaining = g.Args[g.Optind():]
+	}
+}
+
+// IterateAll returns an iterator over the options and non-option arguments of the given argument list, in the order
+// they appear. Non-option arguments are yielded in position as options with C set to 1 and Arg pointing to the
+// argument, as in [ReturnInOrder] mode, which IterateAll always uses regardless of any '+' or '-' prefix on opts.
+// Arguments after a '--' terminator are not yielded; nothing after it is scanned.
+func IterateAll(args []string, opts string) iter.Seq2[*Opt, error] {
+	g := New(args, opts)
+	_ = g.SetOrdering(ReturnInOrder) // A conflicting prefix is overridden, as documented.
+	return iterate(g, g.Getopt, nil)
+}
+
+// All returns an iterator over the options of an existing parser, as returned by [Getopt.Getopt]. Unlike [Iterate],
+// it lets the caller configure the parser before iterating and inspect it afterward, with [Getopt.Remaining], for
+// example.
+//
+// Breaking out of the loop leaves the parser positioned just after the last option yielded, so scanning can resume
+// with another call to All or with direct calls to [Getopt.Getopt].
+func (g *Getopt) All() iter.Seq2[*Opt, error] {
+	return iterate(g, g.Getopt, nil)
+}
+
+// ParseCollect parses all the remaining options with [Getopt.Getopt], continuing past errors, and returns every option
+// found along with every error, joined with [errors.Join]. The error is nil if there were no errors. Each joined error
+// is a [PositionedError], as usual, so [errors.Is] and [errors.As] find the sentinels and concrete error types of all
+// of them. Use [Getopt.Remaining] afterward for the non-option arguments.
+func (g *Getopt) ParseCollect() (opts []Opt, err error) {
+	var errs []error
+	for opt, err := range g.All() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		opts = append(opts, detach(opt))
+	}
+	return opts, errors.Join(errs...)
+}
+
+// ParseMap parses all the remaining options with [Getopt.Getopt] and returns them as a map from [Opt.C] to
+// [Opt.Arg], along with the non-option arguments, as from [Getopt.Operands]. It suits simple programs that only need to
+// know which options were given and with what arguments. An option given without an argument maps to nil. When an
+// option is given more than once, the last occurrence wins, so an earlier argument is lost; use
+// [Getopt.Accumulate] or the parse loop to see every occurrence. Long options that set a Flag all have C set to 0, so
+// they share one entry.
+//
+// Parsing stops at the first error. The options parsed up to that point are returned along with the error, and the
+// operands are nil.
+func (g *Getopt) ParseMap() (map[rune]*string, []string, error) {
+	result := map[rune]*string{}
+	for opt, err := range g.All() {
+		if err != nil {
+			return result, nil, err
+		}
+		if g.isInOrder(opt) {
+			continue
 		}
+		result[opt.C] = detach(opt).Arg
 	}
+	return result, g.Operands(), nil
 }
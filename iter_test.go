@@ -1,6 +1,8 @@
 package getopt_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"iter"
 
@@ -66,6 +68,69 @@ var _ = Describe("Getopt iterator interface", func() {
 		Expect(opts).To(HaveLen(2))
 		Expect(remaining).To(HaveExactElements("arg1", "arg2"))
 	})
+
+	Context("with a context", func() {
+		It("yields everything when not canceled", func() {
+			var remaining []string
+			opts := collect(getopt.IterateContext(context.Background(), []string{"prg", "-a", "x", "-b"}, "ab",
+				&remaining))
+			Expect(opts).To(HaveLen(2))
+			Expect(remaining).To(HaveExactElements("x"))
+		})
+
+		It("stops when canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var remaining []string
+			var opts []Pair[*getopt.Opt, error]
+			for opt, err := range getopt.IterateContext(ctx, []string{"prg", "-a", "-b", "-c"}, "abc", &remaining) {
+				opts = append(opts, Pair[*getopt.Opt, error]{opt, err})
+				cancel()
+			}
+			Expect(opts).To(HaveExactElements(
+				MatchAllFields(Fields{
+					"K": PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})),
+					"V": BeNil(),
+				}),
+				MatchAllFields(Fields{
+					"K": BeNil(),
+					"V": MatchError(context.Canceled),
+				}),
+			))
+			Expect(remaining).To(HaveExactElements("-c"))
+		})
+	})
+
+	Context("with an existing parser", func() {
+		It("honors configuration", func() {
+			g := getopt.New([]string{"prg", "-vvv", "x"}, "v")
+			g.SetCollapseRepeats(true)
+			opts := collect(g.All())
+			Expect(opts).To(HaveExactElements(
+				MatchAllFields(Fields{
+					"K": PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('v'), "Repeat": Equal(3)})),
+					"V": BeNil(),
+				}),
+			))
+			Expect(g.Remaining()).To(HaveExactElements("x"))
+		})
+
+		It("resumes after breaking", func() {
+			g := getopt.New([]string{"prg", "-a", "-b", "-c"}, "abc")
+			for opt := range g.All() {
+				Expect(opt.C).To(Equal('a'))
+				break
+			}
+			Expect(g.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{"C": Equal('b')})))
+			opts := collect(g.All())
+			Expect(opts).To(HaveExactElements(
+				MatchAllFields(Fields{
+					"K": PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('c')})),
+					"V": BeNil(),
+				}),
+			))
+		})
+	})
 })
 
 func ExampleIterate() {
@@ -115,3 +180,161 @@ func ExampleIterateLong() {
 	// Got long option 'bbb'
 	// Got short option 'a'
 }
+
+func ExampleIterateAll() {
+	args := []string{"prg", "-a", "file1", "-b", "file2", "--", "-c"}
+	for opt, err := range getopt.IterateAll(args, "+abc") {
+		if err != nil {
+			_, _ = fmt.Println(err.Error())
+			continue
+		}
+		if opt.C == 1 {
+			_, _ = fmt.Printf("operand %s\n", *opt.Arg)
+		} else {
+			_, _ = fmt.Printf("option %c\n", opt.C)
+		}
+	}
+	// Output:
+	// option a
+	// operand file1
+	// option b
+	// operand file2
+}
+
+var _ = Describe("Iterate remaining arguments", func() {
+	DescribeTable("include the argument that caused an error",
+		func(args []string, opts string, expected ...string) {
+			var remaining []string
+			for _, err := range getopt.Iterate(append([]string{"prg"}, args...), opts, &remaining) {
+				if err != nil {
+					break
+				}
+			}
+			Expect(remaining).To(HaveExactElements(expected))
+		},
+		Entry("unrecognized option", []string{"-a", "-x", "-b", "file"}, "ab", "-x", "-b", "file"),
+		Entry("within a cluster", []string{"-ax", "-b"}, "ab", "-ax", "-b"),
+		Entry("missing argument", []string{"-a", "-o"}, "ao:", "-o"),
+		Entry("after permuted arguments", []string{"file", "-a", "-x", "other"}, "ab", "file", "-x", "other"),
+	)
+
+	It("keep skipped arguments when breaking without an error", func() {
+		var remaining []string
+		for range getopt.Iterate([]string{"prg", "file", "-a", "-b", "other"}, "ab", &remaining) {
+			break
+		}
+		Expect(remaining).To(HaveExactElements("file", "-b", "other"))
+	})
+
+	It("resume after an option when breaking without an error", func() {
+		var remaining []string
+		for range getopt.Iterate([]string{"prg", "-a", "-b", "file"}, "ab", &remaining) {
+			break
+		}
+		Expect(remaining).To(HaveExactElements("-b", "file"))
+	})
+})
+
+var _ = Describe("ParseCollect", func() {
+	It("collects options and every error", func() {
+		gopt := getopt.NewLong([]string{"program", "-a", "-x", "file", "--bravo=1", "-y", "--alpha=2", "-b"}, "ab:",
+			[]getopt.Option{
+				{Name: "alpha", Val: 'a'},
+				{Name: "bravo", HasArg: getopt.RequiredArgument, Val: 'b'},
+			})
+		opts, err := gopt.ParseCollect()
+		Expect(opts).To(HaveExactElements(
+			HaveField("C", 'a'),
+			MatchFields(IgnoreExtras, Fields{
+				"C":   Equal('b'),
+				"Arg": HaveValue(Equal("1")),
+			}),
+		))
+		Expect(err).To(MatchError(getopt.ErrUnrecognized))
+		Expect(err).To(MatchError(getopt.ErrArgumentNotAllowed))
+		Expect(err).To(MatchError(getopt.ErrArgumentRequired))
+		Expect(err.(interface{ Unwrap() []error }).Unwrap()).To(HaveLen(4))
+
+		var positioned getopt.PositionedError
+		Expect(errors.As(err, &positioned)).To(BeTrue())
+		Expect(positioned.Index).To(Equal(2))
+		var notAllowed getopt.ArgumentNotAllowedError
+		Expect(errors.As(err, &notAllowed)).To(BeTrue())
+		Expect(notAllowed.Option).To(Equal("alpha"))
+		var required getopt.ArgumentRequiredError
+		Expect(errors.As(err, &required)).To(BeTrue())
+		Expect(required.Option).To(Equal("b"))
+
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("keeps arguments that permutation moves", func() {
+		gopt := getopt.New([]string{"program", "x", "-b", "1", "y", "-b", "2"}, "b:")
+		opts, err := gopt.ParseCollect()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(HaveExactElements(
+			HaveField("Arg", HaveValue(Equal("1"))),
+			HaveField("Arg", HaveValue(Equal("2"))),
+		))
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y"))
+	})
+
+	It("returns a nil error when parsing succeeds", func() {
+		opts, err := getopt.New([]string{"program", "-a", "x"}, "a").ParseCollect()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ParseMap", func() {
+	longopts := []getopt.Option{
+		{Name: "output", HasArg: getopt.RequiredArgument, Val: 'o'},
+		{Name: "color", HasArg: getopt.OptionalArgument, Val: 'c'},
+	}
+
+	It("maps each option to its last argument", func() {
+		gopt := getopt.NewLong([]string{
+			"program", "-v", "x", "--output", "first", "-o", "second", "--color=auto", "-c", "--", "-y",
+		}, "vo:c::", longopts)
+		opts, operands, err := gopt.ParseMap()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(HaveLen(3))
+		Expect(opts).To(HaveKeyWithValue('v', BeNil()))
+		Expect(opts).To(HaveKeyWithValue('o', HaveValue(Equal("second"))))
+		Expect(opts).To(HaveKeyWithValue('c', BeNil()))
+		Expect(operands).To(HaveExactElements("x", "-y"))
+	})
+
+	It("returns operands in order with ReturnInOrder", func() {
+		opts, operands, err := getopt.New([]string{"program", "x", "-v", "y"}, "-v").ParseMap()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(Equal(map[rune]*string{'v': nil}))
+		Expect(operands).To(HaveExactElements("x", "y"))
+	})
+
+	It("stops at the first error", func() {
+		opts, operands, err := getopt.New([]string{"program", "-v", "-x", "-w"}, "vw").ParseMap()
+		Expect(err).To(MatchError(getopt.ErrUnrecognized))
+		Expect(opts).To(Equal(map[rune]*string{'v': nil}))
+		Expect(operands).To(BeNil())
+	})
+})
+
+func ExampleGetopt_ParseMap() {
+	gopt := getopt.New([]string{"program", "-v", "-o", "out.txt", "in.txt"}, "vo:")
+	opts, operands, err := gopt.ParseMap()
+	if err != nil {
+		panic(err)
+	}
+	if _, ok := opts['v']; ok {
+		_, _ = fmt.Println("verbose")
+	}
+	if output := opts['o']; output != nil {
+		_, _ = fmt.Println("output:", *output)
+	}
+	_, _ = fmt.Println("operands:", operands)
+	// Output:
+	// verbose
+	// output: out.txt
+	// operands: [in.txt]
+}
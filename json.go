@@ -0,0 +1,33 @@
+package getopt
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON encodes the option as a JSON object with the option character as a string in "c", the argument in
+// "arg", and the index of the long option in "longInd". The "arg" member is omitted when there's no argument. For
+// example, "-a value" encodes as {"c":"a","arg":"value","longInd":-1}.
+func (o Opt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		C       string  `json:"c"`
+		Arg     *string `json:"arg,omitempty"`
+		LongInd int     `json:"longInd"`
+	}{
+		C:       string(o.C),
+		Arg:     o.Arg,
+		LongInd: o.LongInd,
+	})
+}
+
+// Snapshot runs the parse loop to completion and returns every option it produced, suitable for logging the whole
+// invocation with [json.Marshal]. Parse errors are skipped, but they are still reported to Out when Opterr is set.
+// Afterward, [Getopt.Remaining] returns the non-option arguments as usual.
+func (g *Getopt) Snapshot() []Opt {
+	var result []Opt
+	for opt, err := range g.All() {
+		if err == nil {
+			result = append(result, detach(opt))
+		}
+	}
+	return result
+}
@@ -0,0 +1,47 @@
+package getopt_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("JSON", func() {
+	It("encodes options", func() {
+		gopt := NewLong([]string{"program", "-a", "value", "-b", "--charlie=x", "file"}, "a:b", []Option{
+			{Name: "charlie", HasArg: RequiredArgument, Val: 'c'},
+		})
+		gopt.Opterr = false
+		data, err := json.Marshal(gopt.Snapshot())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`[
+			{"c": "a", "arg": "value", "longInd": -1},
+			{"c": "b", "longInd": -1},
+			{"c": "c", "arg": "x", "longInd": 0}
+		]`))
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("keeps arguments of options after operands", func() {
+		gopt := New([]string{"program", "x", "-b", "1", "y", "-b", "2"}, "b:")
+		Expect(gopt.Snapshot()).To(HaveExactElements(
+			HaveField("Arg", HaveValue(Equal("1"))),
+			HaveField("Arg", HaveValue(Equal("2"))),
+		))
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "y"))
+	})
+
+	It("skips errors", func() {
+		gopt := New([]string{"program", "-x", "-a"}, "a")
+		gopt.Opterr = false
+		Expect(gopt.Snapshot()).To(HaveExactElements(HaveField("C", 'a')))
+	})
+
+	It("encodes pointers", func() {
+		arg := "1"
+		Expect(json.Marshal(&Opt{C: 1, Arg: &arg, LongInd: -1})).
+			To(MatchJSON(`{"c": "\u0001", "arg": "1", "longInd": -1}`))
+	})
+})
@@ -0,0 +1,37 @@
+package getopt
+
+import (
+	"bufio"
+	"io"
+)
+
+// ScanLines parses each line read from r as a separate command line, for interactive programs that read commands one
+// line at a time. Each line is split into arguments with [SplitArgs] and parsed with a new parser for the short option
+// specification opts, as by [New]. The lines don't include a program name.
+//
+// ScanLines calls fn with each option, or with each error, in the same way as [Getopt.All]. A line that can't be split
+// is reported to fn with a nil option and the [SplitArgsError], and nothing on it is parsed. Blank lines produce no
+// calls. Scanning continues as long as fn returns true; when it returns false, ScanLines returns nil without reading
+// any more lines. Otherwise, ScanLines returns any error from reading r, or nil at the end of input.
+//
+// Non-option arguments are not passed to fn unless opts begins with '-', in which case they appear in order as options
+// with C set to 1. Since each line is split on its own, a backslash at the end of a line is an error rather than a
+// line continuation.
+func ScanLines(r io.Reader, opts string, fn func(*Opt, error) bool) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		args, err := SplitArgs(scanner.Text())
+		if err != nil {
+			if !fn(nil, err) {
+				return nil
+			}
+			continue
+		}
+		for opt, err := range NewFromArgs("", args, opts).All() {
+			if !fn(opt, err) {
+				return nil
+			}
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,105 @@
+package getopt_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+// lineResult records one call of the function passed to ScanLines.
+type lineResult struct {
+	C   rune
+	Arg string
+	Err error
+}
+
+// scanLines runs ScanLines over input and records each call, stopping after limit calls if limit is positive.
+func scanLines(input, opts string, limit int) ([]lineResult, error) {
+	var results []lineResult
+	err := ScanLines(strings.NewReader(input), opts, func(opt *Opt, err error) bool {
+		result := lineResult{Err: err}
+		if opt != nil {
+			result.C = opt.C
+			if opt.Arg != nil {
+				result.Arg = *opt.Arg
+			}
+		}
+		results = append(results, result)
+		return limit <= 0 || len(results) < limit
+	})
+	return results, err
+}
+
+var _ = Describe("ScanLines", func() {
+	It("parses each line separately", func() {
+		results, err := scanLines("-a -b 'x y'\n\n-ab z\n", "ab:", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveExactElements(
+			lineResult{C: 'a'},
+			lineResult{C: 'b', Arg: "x y"},
+			lineResult{C: 'a'},
+			lineResult{C: 'b', Arg: "z"},
+		))
+	})
+
+	It("reports errors and continues", func() {
+		results, err := scanLines("-c\n-a 'oops\n-a\n", "a", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].Err).To(MatchError(ErrUnrecognized))
+		Expect(results[1].Err).To(MatchError(SplitArgsError{Pos: 3, Message: "unterminated single quote"}))
+		Expect(results[2]).To(Equal(lineResult{C: 'a'}))
+	})
+
+	It("returns operands in order with a '-' prefix", func() {
+		results, err := scanLines("get -v key\n", "-v", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveExactElements(
+			lineResult{C: 1, Arg: "get"},
+			lineResult{C: 'v'},
+			lineResult{C: 1, Arg: "key"},
+		))
+	})
+
+	It("stops when the function returns false", func() {
+		results, err := scanLines("-a -a\n-a\n", "a", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+	})
+
+	It("returns read errors", func() {
+		readErr := errors.New("read failed")
+		err := ScanLines(iotest.ErrReader(readErr), "a", func(*Opt, error) bool {
+			Fail("unexpected call")
+			return true
+		})
+		Expect(err).To(MatchError(readErr))
+	})
+})
+
+func ExampleScanLines() {
+	input := "-v\n-o 'out file.txt'\n-x\n"
+	err := ScanLines(strings.NewReader(input), "vo:", func(opt *Opt, err error) bool {
+		switch {
+		case err != nil:
+			_, _ = fmt.Println("error:", err)
+		case opt.Arg != nil:
+			_, _ = fmt.Printf("-%c %s\n", opt.C, *opt.Arg)
+		default:
+			_, _ = fmt.Printf("-%c\n", opt.C)
+		}
+		return true
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// -v
+	// -o out file.txt
+	// error: unrecognized option '-x'
+}
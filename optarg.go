@@ -0,0 +1,62 @@
+package getopt
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// name returns the option name and prefix to use when describing o in an error.
+func (o *Opt) name() (option, prefix string) {
+	if o.Long != nil {
+		return o.Long.Name, argumentTerminator
+	}
+	return string(o.C), dash
+}
+
+// convert parses the option's argument with parse. If there's no argument, it returns an [ArgumentRequiredError]. If
+// parse fails, it returns an [InvalidArgumentError].
+func convert[T any](o *Opt, parse func(string) (T, error)) (T, error) {
+	option, prefix := o.name()
+	if o.Arg == nil {
+		var zero T
+		return zero, ArgumentRequiredError{
+			Option: option,
+			Prefix: prefix,
+		}
+	}
+	result, err := parse(*o.Arg)
+	if err != nil {
+		// Strip the function name and input that strconv includes; the InvalidArgumentError already says both.
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) {
+			err = numErr.Err
+		}
+		return result, InvalidArgumentError{
+			Option: option,
+			Prefix: prefix,
+			Arg:    *o.Arg,
+			Err:    err,
+		}
+	}
+	return result, nil
+}
+
+// The following methods parse the option's argument into other types. If the option has no argument, they return an
+// [ArgumentRequiredError]. If the argument is malformed, they return an [InvalidArgumentError] that wraps the parsing
+// error. Either way, the error names the option.
+
+// Int parses the option's argument as a base-10 integer.
+func (o *Opt) Int() (int, error) {
+	return convert(o, strconv.Atoi)
+}
+
+// Bool parses the option's argument as a boolean using the rules of [strconv.ParseBool].
+func (o *Opt) Bool() (bool, error) {
+	return convert(o, strconv.ParseBool)
+}
+
+// Duration parses the option's argument as a duration using the rules of [time.ParseDuration].
+func (o *Opt) Duration() (time.Duration, error) {
+	return convert(o, time.ParseDuration)
+}
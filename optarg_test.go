@@ -0,0 +1,59 @@
+package getopt_test
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Typed arguments", func() {
+	longopts := []Option{
+		{Name: "count", HasArg: RequiredArgument, Val: 'n'},
+		{Name: "color", HasArg: OptionalArgument, Val: 'c'},
+	}
+
+	parse := func(args ...string) *Opt {
+		gopt := NewLong(append([]string{"program"}, args...), "n:c::t:", longopts)
+		opt, err := gopt.Getopt()
+		Expect(err).NotTo(HaveOccurred())
+		return opt
+	}
+
+	It("parses integers", func() {
+		Expect(parse("-n", "42").Int()).To(Equal(42))
+	})
+
+	It("parses booleans", func() {
+		Expect(parse("--color=true").Bool()).To(BeTrue())
+	})
+
+	It("parses durations", func() {
+		Expect(parse("-t1m30s").Duration()).To(Equal(90 * time.Second))
+	})
+
+	It("reports malformed short option arguments", func() {
+		_, err := parse("-nabc").Int()
+		Expect(err).To(MatchError("invalid argument 'abc' for '-n': invalid syntax"))
+		Expect(err).To(MatchError(ErrInvalidArgument))
+		Expect(err).To(MatchError(strconv.ErrSyntax))
+	})
+
+	It("reports malformed long option arguments", func() {
+		_, err := parse("--count", "99999999999999999999").Int()
+		Expect(err).To(MatchError("invalid argument '99999999999999999999' for '--count': value out of range"))
+	})
+
+	It("reports malformed durations", func() {
+		_, err := parse("-t", "soon").Duration()
+		Expect(err).To(MatchError(`invalid argument 'soon' for '-t': time: invalid duration "soon"`))
+	})
+
+	It("reports missing arguments", func() {
+		_, err := parse("--color").Bool()
+		Expect(err).To(MatchError("option '--color' requires an argument"))
+		Expect(err).To(MatchError(ErrArgumentRequired))
+	})
+})
@@ -0,0 +1,41 @@
+package getopt
+
+// OrderedItem is one option returned by [ParseOrdered], along with the non-option arguments that appeared on the
+// command line between the previous option and this one.
+type OrderedItem struct {
+	Opt      *Opt
+	Operands []string
+}
+
+// ParseOrdered parses every option in args in exactly the order it appears on the command line. It is meant for
+// programs that must forward options to another program without disturbing their order.
+//
+// Arguments are never permuted, regardless of any '+' or '-' prefix on opts. Non-option arguments that appear between
+// options are returned in the Operands field of the item for the option that follows them. Non-option arguments after
+// the last option, along with everything after a '--' terminator, are returned as the trailing slice. The '--' itself
+// is consumed, so a caller replaying the arguments should emit "--" before the trailing arguments if any of them might
+// look like options. Replaying each item's Operands, then its option, and finally the trailing arguments preserves the
+// relative order of options and non-option arguments. It doesn't reproduce the original command line exactly, though:
+// a cluster such as "-ab" comes back as separate options, and the original spelling of an argument, attached as in
+// "-ofile" or separate as in "-o file", isn't kept.
+//
+// Parsing stops at the first error. The items parsed up to that point are returned along with the error.
+func ParseOrdered(args []string, opts string, longOptions []Option) ([]OrderedItem, []string, error) {
+	g := NewLong(args, opts, longOptions)
+	g.shortOptions.Ordering = ReturnInOrder
+
+	var result []OrderedItem
+	var pending []string
+	for opt, err := g.Getopt(); opt != nil || err != nil; opt, err = g.Getopt() {
+		if err != nil {
+			return result, nil, err
+		}
+		if g.isInOrder(opt) {
+			pending = append(pending, *opt.Arg)
+			continue
+		}
+		result = append(result, OrderedItem{Opt: opt, Operands: pending})
+		pending = nil
+	}
+	return result, append(pending, g.Args[g.Optind():]...), nil
+}
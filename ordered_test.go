@@ -0,0 +1,71 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("ParseOrdered", func() {
+	longopts := []Option{
+		{Name: "alpha", HasArg: NoArgument, Val: 'a'},
+		{Name: "bravo", HasArg: RequiredArgument, Val: 'b'},
+	}
+
+	It("preserves interleaved operands", func() {
+		items, trailing, err := ParseOrdered([]string{
+			"program", "x", "-a", "y", "z", "--bravo", "1", "-ab2", "w", "--alpha", "v", "--", "-a", "u",
+		}, "ab:", longopts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(HaveExactElements(
+			MatchAllFields(Fields{
+				"Opt":      PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})),
+				"Operands": HaveExactElements("x"),
+			}),
+			MatchAllFields(Fields{
+				"Opt": PointTo(MatchFields(IgnoreExtras, Fields{
+					"C":       Equal('b'),
+					"Arg":     HaveValue(Equal("1")),
+					"LongInd": Equal(1),
+				})),
+				"Operands": HaveExactElements("y", "z"),
+			}),
+			MatchAllFields(Fields{
+				"Opt":      PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})),
+				"Operands": BeEmpty(),
+			}),
+			MatchAllFields(Fields{
+				"Opt": PointTo(MatchFields(IgnoreExtras, Fields{
+					"C":   Equal('b'),
+					"Arg": HaveValue(Equal("2")),
+				})),
+				"Operands": BeEmpty(),
+			}),
+			MatchAllFields(Fields{
+				"Opt": PointTo(MatchFields(IgnoreExtras, Fields{
+					"C":       Equal('a'),
+					"LongInd": Equal(0),
+				})),
+				"Operands": HaveExactElements("w"),
+			}),
+		))
+		Expect(trailing).To(HaveExactElements("v", "-a", "u"))
+	})
+
+	It("ignores the ordering prefix", func() {
+		items, trailing, err := ParseOrdered([]string{"program", "x", "-a"}, "+a", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(HaveExactElements(MatchAllFields(Fields{
+			"Opt":      PointTo(MatchFields(IgnoreExtras, Fields{"C": Equal('a')})),
+			"Operands": HaveExactElements("x"),
+		})))
+		Expect(trailing).To(BeEmpty())
+	})
+
+	It("stops at the first error", func() {
+		items, _, err := ParseOrdered([]string{"program", "-a", "-c", "-a"}, "a", nil)
+		Expect(err).To(MatchError("unrecognized option '-c'"))
+		Expect(items).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,93 @@
+package getopt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const responseFilePrefix = "@"
+
+// ErrResponseFileCycle is wrapped by the [ResponseFileError] returned when a response file refers to itself, directly
+// or indirectly.
+var ErrResponseFileCycle = errors.New("response file includes itself")
+
+// ResponseFileError is returned by [ExpandResponseFiles] when the response file at Path can't be expanded. Err
+// describes the problem.
+type ResponseFileError struct {
+	Path string
+	Err  error
+}
+
+func (e ResponseFileError) Error() string {
+	return fmt.Sprintf("response file '%s': %v", e.Path, e.Err)
+}
+
+// Unwrap returns Err.
+func (e ResponseFileError) Unwrap() error {
+	return e.Err
+}
+
+// ExpandResponseFiles returns a copy of args in which each argument of the form "@path" is replaced by the
+// whitespace-separated words of the named response file. Response files may refer to other response files, which are
+// expanded in turn; a [ResponseFileError] wrapping [ErrResponseFileCycle] is returned if a file refers back to one that
+// is already being expanded. The open callback provides the contents of each file, which lets callers control how
+// paths are resolved. If the reader it returns is also an [io.Closer], it is closed after reading.
+//
+// As with [New], args[0] is taken to be the program name, so it is never expanded. A lone "@" is left alone. The result
+// is suitable for passing to [New] or [NewLong].
+func ExpandResponseFiles(args []string, open func(string) (io.Reader, error)) ([]string, error) {
+	if len(args) == 0 {
+		return []string{}, nil
+	}
+	result := []string{args[0]}
+	return expandResponseFiles(result, args[1:], open, nil)
+}
+
+// expandResponseFiles appends args to result, expanding response files. Active holds the paths of the response files
+// currently being expanded.
+func expandResponseFiles(
+	result, args []string, open func(string) (io.Reader, error), active []string,
+) ([]string, error) {
+	for _, arg := range args {
+		path, ok := strings.CutPrefix(arg, responseFilePrefix)
+		if !ok || path == "" {
+			result = append(result, arg)
+			continue
+		}
+		if slices.Contains(active, path) {
+			return nil, ResponseFileError{Path: path, Err: ErrResponseFileCycle}
+		}
+		words, err := readResponseFile(path, open)
+		if err != nil {
+			return nil, ResponseFileError{Path: path, Err: err}
+		}
+		result, err = expandResponseFiles(result, words, open, append(active, path))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readResponseFile returns the whitespace-separated words of the response file at path.
+func readResponseFile(path string, open func(string) (io.Reader, error)) (words []string, err error) {
+	r, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := r.(io.Closer); ok {
+		defer func() {
+			err = errors.Join(err, c.Close())
+		}()
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		words = append(words, scanner.Text())
+	}
+	return words, scanner.Err()
+}
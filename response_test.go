@@ -0,0 +1,73 @@
+package getopt_test
+
+import (
+	"io"
+	"io/fs"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+// fileMap returns a callback for ExpandResponseFiles that reads from the given in-memory files.
+func fileMap(files map[string]string) func(string) (io.Reader, error) {
+	return func(path string) (io.Reader, error) {
+		content, ok := files[path]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return strings.NewReader(content), nil
+	}
+}
+
+var _ = Describe("ExpandResponseFiles", func() {
+	It("replaces response files with their contents", func() {
+		argv := []string{"program", "-a", "@opts", "x", "@", "@more"}
+		args, err := ExpandResponseFiles(argv, fileMap(map[string]string{
+			"opts": "-b  one\n\t-c two\n",
+			"more": "",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(HaveExactElements("program", "-a", "-b", "one", "-c", "two", "x", "@"))
+	})
+
+	It("expands nested response files", func() {
+		args, err := ExpandResponseFiles([]string{"program", "@outer"}, fileMap(map[string]string{
+			"outer": "-a @inner -d",
+			"inner": "-b @leaf",
+			"leaf":  "-c",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(HaveExactElements("program", "-a", "-b", "-c", "-d"))
+	})
+
+	It("allows the same file more than once", func() {
+		args, err := ExpandResponseFiles([]string{"program", "@f", "@f"}, fileMap(map[string]string{
+			"f": "-a",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(HaveExactElements("program", "-a", "-a"))
+	})
+
+	It("detects cycles", func() {
+		_, err := ExpandResponseFiles([]string{"program", "@a"}, fileMap(map[string]string{
+			"a": "@b",
+			"b": "-x @a",
+		}))
+		Expect(err).To(MatchError(ErrResponseFileCycle))
+		Expect(err).To(MatchError("response file 'a': response file includes itself"))
+	})
+
+	It("reports missing files", func() {
+		_, err := ExpandResponseFiles([]string{"program", "@missing"}, fileMap(nil))
+		Expect(err).To(MatchError(fs.ErrNotExist))
+		Expect(err).To(MatchError(ResponseFileError{Path: "missing", Err: fs.ErrNotExist}))
+	})
+
+	It("does not expand the program name", func() {
+		args, err := ExpandResponseFiles([]string{"@program"}, fileMap(nil))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(HaveExactElements("@program"))
+	})
+})
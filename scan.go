@@ -0,0 +1,33 @@
+package getopt
+
+// Scan runs the parse loop to completion, calling the handler registered for each option's character, [Opt.C], with
+// the option's argument. Non-option arguments in [ReturnInOrder] mode go to the handler for the in-order rune (1 by
+// default; see [Getopt.SetInOrderRune]).
+//
+// Options without a handler of their own go to the default handler registered for 0, if there is one, and are
+// ignored otherwise. Long options that store their Val through a Flag have already done their work, so they are never
+// passed to a handler.
+//
+// Scanning stops at the first parse error or the first error returned by a handler, and that error is returned. The
+// parser is left positioned after the option that caused it.
+func (g *Getopt) Scan(handlers map[rune]func(arg *string) error) error {
+	for opt, err := range g.All() {
+		if err != nil {
+			return err
+		}
+		if opt.C == 0 {
+			continue
+		}
+		handler, ok := handlers[opt.C]
+		if !ok {
+			handler = handlers[0]
+		}
+		if handler == nil {
+			continue
+		}
+		if err := handler(opt.Arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
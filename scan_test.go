@@ -0,0 +1,63 @@
+package getopt_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Scan", func() {
+	var flag rune
+	longopts := []Option{
+		{Name: "output", HasArg: RequiredArgument, Val: 'o'},
+		{Name: "quiet", Flag: &flag, Val: 'q'},
+	}
+
+	It("calls handlers for each option", func() {
+		var calls []string
+		gopt := NewLong([]string{"program", "-v", "--output", "x", "--quiet", "-z", "file"}, "vo:z", longopts)
+		Expect(gopt.Scan(map[rune]func(*string) error{
+			'v': func(arg *string) error {
+				Expect(arg).To(BeNil())
+				calls = append(calls, "v")
+				return nil
+			},
+			'o': func(arg *string) error {
+				calls = append(calls, "o="+*arg)
+				return nil
+			},
+		})).To(Succeed())
+		Expect(calls).To(HaveExactElements("v", "o=x"))
+		Expect(flag).To(Equal('q'))
+		Expect(gopt.Remaining()).To(HaveExactElements("file"))
+	})
+
+	It("calls the default handler for unhandled options", func() {
+		var count int
+		gopt := New([]string{"program", "-a", "-b", "-c"}, "abc")
+		Expect(gopt.Scan(map[rune]func(*string) error{
+			'b': func(*string) error { return nil },
+			0: func(*string) error {
+				count++
+				return nil
+			},
+		})).To(Succeed())
+		Expect(count).To(Equal(2))
+	})
+
+	It("stops at a handler error", func() {
+		failure := errors.New("failure")
+		gopt := New([]string{"program", "-a", "-b"}, "ab")
+		Expect(gopt.Scan(map[rune]func(*string) error{
+			'a': func(*string) error { return failure },
+		})).To(MatchError(failure))
+		Expect(gopt.Optind()).To(Equal(2))
+	})
+
+	It("stops at a parse error", func() {
+		gopt := New([]string{"program", "-x", "-a"}, "a")
+		Expect(gopt.Scan(nil)).To(MatchError(ErrUnrecognized))
+	})
+})
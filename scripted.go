@@ -0,0 +1,41 @@
+package getopt
+
+// OptionScanner is the part of [Getopt] that a parse loop needs. Code that accepts an OptionScanner instead of a
+// *Getopt can be tested with [NewScripted] instead of real argument lists.
+type OptionScanner interface {
+	Getopt() (*Opt, error)
+	Optind() int
+}
+
+// ScriptedResult is one result for the scanner returned by [NewScripted] to return.
+type ScriptedResult struct {
+	Opt *Opt
+	Err error
+}
+
+// scripted is an [OptionScanner] that returns predetermined results.
+type scripted struct {
+	results []ScriptedResult
+	next    int
+}
+
+// NewScripted returns an [OptionScanner] whose Getopt method returns the elements of seq in order, and then a nil
+// option and nil error, just as [Getopt.Getopt] does once scanning is finished. Its Optind method behaves as though
+// each result came from its own argument: it returns 1 before the first call to Getopt and one more for each result
+// returned since.
+func NewScripted(seq []ScriptedResult) OptionScanner {
+	return &scripted{results: seq}
+}
+
+func (s *scripted) Getopt() (*Opt, error) {
+	if s.next == len(s.results) {
+		return nil, nil
+	}
+	result := s.results[s.next]
+	s.next++
+	return result.Opt, result.Err
+}
+
+func (s *scripted) Optind() int {
+	return s.next + 1
+}
@@ -0,0 +1,59 @@
+package getopt_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+// countVerbose is an example of code that consumes options from an OptionScanner.
+func countVerbose(scanner OptionScanner) (int, error) {
+	count := 0
+	for opt, err := scanner.Getopt(); opt != nil || err != nil; opt, err = scanner.Getopt() {
+		if err != nil {
+			return count, err
+		}
+		if opt.C == 'v' {
+			count++
+		}
+	}
+	return count, nil
+}
+
+var _ = Describe("NewScripted", func() {
+	It("returns the scripted results in order", func() {
+		arg := "x"
+		scanner := NewScripted([]ScriptedResult{
+			{Opt: &Opt{C: 'a', LongInd: -1}},
+			{Err: UnrecognizedOptionError{Option: "q", Prefix: "-"}},
+			{Opt: &Opt{C: 'b', Arg: &arg, LongInd: -1}},
+		})
+		Expect(scanner.Optind()).To(Equal(1))
+		Expect(scanner.Getopt()).To(HaveField("C", 'a'))
+		_, err := scanner.Getopt()
+		Expect(err).To(MatchError(ErrUnrecognized))
+		Expect(scanner.Getopt()).To(HaveField("Arg", HaveValue(Equal("x"))))
+		Expect(scanner.Optind()).To(Equal(4))
+		Expect(scanner.Getopt()).To(BeNil())
+		Expect(scanner.Getopt()).To(BeNil())
+		Expect(scanner.Optind()).To(Equal(4))
+	})
+
+	It("is interchangeable with a real parser", func() {
+		Expect(countVerbose(New([]string{"program", "-vxv"}, "vx"))).To(Equal(2))
+		Expect(countVerbose(NewScripted([]ScriptedResult{{Opt: &Opt{C: 'v'}}, {Opt: &Opt{C: 'v'}}}))).To(Equal(2))
+		Expect(countVerbose(NewScripted(nil))).To(Equal(0))
+	})
+})
+
+func ExampleNewScripted() {
+	scanner := NewScripted([]ScriptedResult{
+		{Opt: &Opt{C: 'v', LongInd: -1}},
+		{Err: ArgumentRequiredError{Option: "o", Prefix: "-"}},
+	})
+	count, err := countVerbose(scanner)
+	_, _ = fmt.Println(count, err)
+	// Output: 1 option '-o' requires an argument
+}
@@ -0,0 +1,93 @@
+package getopt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgsError is returned by [SplitArgs] when a command line can't be split. Pos is the byte offset in the line of
+// the problem, which Message describes.
+type SplitArgsError struct {
+	Pos     int
+	Message string
+}
+
+func (e SplitArgsError) Error() string {
+	return fmt.Sprintf("cannot split arguments at offset %d: %s", e.Pos, e.Message)
+}
+
+// doubleQuoteEscapable lists the characters that a backslash escapes inside double quotes.
+const doubleQuoteEscapable = "$`\"\\\n"
+
+// SplitArgs splits a command line into arguments the way a POSIX shell would, without performing any expansions.
+// Arguments are separated by unquoted spaces, tabs, and newlines. Within an argument:
+//   - A backslash outside quotes preserves the following character literally. A backslash followed by a newline is
+//     removed entirely.
+//   - Single quotes preserve everything up to the next single quote literally, including backslashes, so a single quote
+//     can't appear inside single quotes. Use an escaped quote between two quoted parts instead, as in the example
+//     below.
+//   - Double quotes preserve everything up to the next unescaped double quote, except that a backslash escapes '$',
+//     '`', '"', '\', or newline.
+//
+// For example, this line holds the three arguments -a, "b c", and "it's":
+//
+//	-a "b c" 'it'\''s'
+//
+// Quotes can produce empty arguments, as in "". A [SplitArgsError] is returned for an unterminated quote or a trailing
+// backslash.
+//
+// The result does not include a program name, so prepend one before passing it to [New].
+func SplitArgs(line string) ([]string, error) {
+	result := []string{}
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch c {
+		case ' ', '\t', '\n':
+			if inWord {
+				result = append(result, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		case '\\':
+			if i+1 == len(line) {
+				return nil, SplitArgsError{Pos: i, Message: "trailing backslash"}
+			}
+			i++
+			if line[i] == '\n' {
+				continue
+			}
+			_ = word.WriteByte(line[i])
+		case '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end == -1 {
+				return nil, SplitArgsError{Pos: i, Message: "unterminated single quote"}
+			}
+			_, _ = word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+		case '"':
+			start := i
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte(doubleQuoteEscapable, line[i+1]) != -1 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				_ = word.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, SplitArgsError{Pos: start, Message: "unterminated double quote"}
+			}
+		default:
+			_ = word.WriteByte(c)
+		}
+		inWord = true
+	}
+	if inWord {
+		result = append(result, word.String())
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("SplitArgs", func() {
+	DescribeTable("splits command lines",
+		func(line string, expected []string) {
+			Expect(SplitArgs(line)).To(HaveExactElements(expected))
+		},
+		Entry("empty", "", []string{}),
+		Entry("blank", " \t\n ", []string{}),
+		Entry("plain words", "-a  -b\tfile\n", []string{"-a", "-b", "file"}),
+		Entry("double quotes", `-o "a b" c`, []string{"-o", "a b", "c"}),
+		Entry("single quotes", `'a b' 'c"d'`, []string{"a b", `c"d`}),
+		Entry("single quote inside single quotes", `'a'\''b'`, []string{"a'b"}),
+		Entry("backslash in single quotes", `'a\b'`, []string{`a\b`}),
+		Entry("escaped space", `a\ b c`, []string{"a b", "c"}),
+		Entry("escaped quote", `a\'b`, []string{"a'b"}),
+		Entry("escapes in double quotes", `"a\"b\\c\d\$"`, []string{`a"b\c\d$`}),
+		Entry("line continuation", "a\\\nb \"c\\\nd\"", []string{"ab", "cd"}),
+		Entry("adjacent quoted parts", `--name="a b"'c d'e`, []string{"--name=a bc de"}),
+		Entry("empty arguments", `"" ''`, []string{"", ""}),
+		Entry("multibyte text", `"héllo wörld" ünï`, []string{"héllo wörld", "ünï"}),
+	)
+
+	DescribeTable("rejects malformed command lines",
+		func(line string, expected error) {
+			Expect(SplitArgs(line)).Error().To(MatchError(expected))
+		},
+		Entry("unterminated single quote", `a 'b`, SplitArgsError{Pos: 2, Message: "unterminated single quote"}),
+		Entry("backslash before single quote", `'a\'b'`, SplitArgsError{Pos: 5, Message: "unterminated single quote"}),
+		Entry("unterminated double quote", `a "b\"`, SplitArgsError{Pos: 2, Message: "unterminated double quote"}),
+		Entry("trailing backslash", `a\`, SplitArgsError{Pos: 1, Message: "trailing backslash"}),
+	)
+
+	It("feeds the parser", func() {
+		args, err := SplitArgs(`-o "out file" input`)
+		Expect(err).NotTo(HaveOccurred())
+		gopt := New(append([]string{"program"}, args...), "o:")
+		Expect(gopt.Getopt()).To(HaveValue(HaveField("Arg", HaveValue(Equal("out file")))))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("input"))
+	})
+})
@@ -9,6 +9,7 @@ import (
 type optinfo struct {
 	Ordering Ordering
 	W        bool
+	Numeric  bool
 	Opts     map[rune]ArgumentDisposition
 }
 
@@ -17,7 +18,13 @@ func (inf *optinfo) HasOpt(c rune) bool {
 	return ok
 }
 
-func parseShortOptionSpec(options string) optinfo {
+// numericMarker is the character in a short option specification that enables numeric options such as "-10".
+const numericMarker = '#'
+
+// parseShortOptionSpec interprets a short option specification. It always returns the best interpretation it can, but
+// if the specification has problems, it also returns an error describing the first one. A [SpecSyntaxError] reports
+// characters in unexpected places, and a [DuplicateOptionError] reports an option letter that appears twice.
+func parseShortOptionSpec(options string) (optinfo, error) {
 	const (
 		inorderPrefix = "-"
 		posixPrefix   = "+"
@@ -31,17 +38,41 @@ func parseShortOptionSpec(options string) optinfo {
 	default:
 		result.Ordering = Permute
 	}
+	optrunes := []rune(options)
+	i := 0
 	if strings.HasPrefix(options, posixPrefix) || strings.HasPrefix(options, inorderPrefix) {
-		options = options[1:]
+		i++
 	}
-	if strings.HasPrefix(options, ":") {
+	if i < len(optrunes) && optrunes[i] == ':' {
 		// Here we would mark to suppress printing errors, but we just always do that.
-		options = options[1:]
+		i++
 	}
 	result.Opts = map[rune]ArgumentDisposition{}
-	optrunes := []rune(options)
-	for i := 0; i < len(optrunes); {
+	var err error
+	fail := func(e error) {
+		if err == nil {
+			err = e
+		}
+	}
+	for i < len(optrunes) {
 		c := optrunes[i]
+		if c == numericMarker {
+			result.Numeric = true
+			i++
+			continue
+		}
+		switch c {
+		case ':':
+			fail(SpecSyntaxError{Pos: i, Message: "':' does not follow an option letter"})
+		case ';':
+			fail(SpecSyntaxError{Pos: i, Message: "';' does not follow 'W'"})
+		}
+		if result.HasOpt(c) {
+			fail(DuplicateOptionError{
+				Name:   string(c),
+				Prefix: dash,
+			})
+		}
 		result.Opts[c] = NoArgument
 		i++
 		if c == 'W' && i < len(optrunes) && optrunes[i] == ';' {
@@ -56,7 +87,10 @@ func parseShortOptionSpec(options string) optinfo {
 				result.Opts[c] = OptionalArgument
 				i++
 			}
+			if i < len(optrunes) && optrunes[i] == ':' {
+				fail(SpecSyntaxError{Pos: i, Message: "too many ':' after option letter"})
+			}
 		}
 	}
-	return result
+	return result, err
 }
@@ -0,0 +1,76 @@
+package getopt
+
+// ShortOption describes one short option of a [MultilineSpec]: the option character and whether it takes an argument.
+type ShortOption struct {
+	Rune        rune
+	Disposition ArgumentDisposition
+}
+
+// MultilineSpec is a structured alternative to the short option specification string accepted by [New], for programs
+// with so many short options that the string form is hard to read. Each entry of Options corresponds to one option
+// letter and its colons in the string form. WExtension corresponds to "W;", and Numeric corresponds to '#'. There is no
+// counterpart to the '+' and '-' prefixes; use [Getopt.SetOrdering] instead.
+//
+// For example, the specification "ab:c::W;" can be written as
+//
+//	getopt.MultilineSpec{
+//		Options: []getopt.ShortOption{
+//			{Rune: 'a', Disposition: getopt.NoArgument},
+//			{Rune: 'b', Disposition: getopt.RequiredArgument},
+//			{Rune: 'c', Disposition: getopt.OptionalArgument},
+//		},
+//		WExtension: true,
+//	}
+type MultilineSpec struct {
+	Options    []ShortOption
+	WExtension bool
+	Numeric    bool
+}
+
+// optinfo returns the parsed form of the specification. If an option character appears more than once, the last entry
+// for it wins.
+func (s MultilineSpec) optinfo() optinfo {
+	result := optinfo{
+		Ordering: Permute,
+		Numeric:  s.Numeric,
+		Opts:     make(map[rune]ArgumentDisposition, len(s.Options)),
+	}
+	for _, o := range s.Options {
+		result.Opts[o.Rune] = o.Disposition
+	}
+	if s.WExtension {
+		result.W = true
+		if !result.HasOpt('W') {
+			result.Opts['W'] = NoArgument
+		}
+	}
+	return result
+}
+
+// NewFromSpec is like [New], but it takes the short options from spec instead of a specification string. Scanning uses
+// [Permute] ordering unless changed with [Getopt.SetOrdering].
+func NewFromSpec(args []string, spec MultilineSpec) *Getopt {
+	return newParser(args, spec.optinfo())
+}
+
+// Spec is a compiled short option specification, for programs that create many parsers with the same options. Create
+// one with [CompileSpec] and pass it to [NewWithSpec] to skip parsing the specification string each time. A Spec is
+// never modified after it's compiled, so it may be shared among goroutines.
+type Spec struct {
+	info optinfo
+}
+
+// CompileSpec parses the short option specification opts, described at [New]. It returns the same errors as
+// [NewStrict] when the specification is malformed.
+func CompileSpec(opts string) (*Spec, error) {
+	info, err := parseShortOptionSpec(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{info: info}, nil
+}
+
+// NewWithSpec is like [New], but it takes the short options from a specification compiled with [CompileSpec].
+func NewWithSpec(args []string, spec *Spec) *Getopt {
+	return newParser(args, spec.info)
+}
@@ -0,0 +1,160 @@
+package getopt_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("NewFromSpec", func() {
+	spec := MultilineSpec{
+		Options: []ShortOption{
+			{Rune: 'a', Disposition: NoArgument},
+			{Rune: 'b', Disposition: RequiredArgument},
+			{Rune: 'c', Disposition: OptionalArgument},
+		},
+	}
+
+	It("defines the same options as the string form", func() {
+		gopt := NewFromSpec([]string{"program"}, spec)
+		Expect(gopt.ShortOptions()).To(Equal(New([]string{"program"}, "ab:c::").ShortOptions()))
+		Expect(gopt.Ordering()).To(Equal(Permute))
+		Expect(gopt.WEnabled()).To(BeFalse())
+	})
+
+	It("parses options", func() {
+		gopt := NewFromSpec([]string{"program", "x", "-a", "-b", "1", "-c"}, spec)
+		Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('b'),
+			"Arg": HaveValue(Equal("1")),
+		})))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('c'),
+			"Arg": BeNil(),
+		})))
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x"))
+	})
+
+	It("enables extensions", func() {
+		gopt := NewFromSpec([]string{"program", "-5"}, MultilineSpec{WExtension: true, Numeric: true})
+		Expect(gopt.WEnabled()).To(BeTrue())
+		Expect(gopt.ShortOptions()).To(HaveKeyWithValue('W', RequiredArgument))
+		Expect(gopt.Getopt()).To(HaveValue(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal(NumberOption),
+			"Arg": HaveValue(Equal("5")),
+		})))
+	})
+
+	It("accepts another ordering", func() {
+		gopt := NewFromSpec([]string{"program", "x", "-a"}, spec)
+		Expect(gopt.SetOrdering(RequireOrder)).To(Succeed())
+		Expect(gopt.Getopt()).To(BeNil())
+		Expect(gopt.Remaining()).To(HaveExactElements("x", "-a"))
+	})
+})
+
+func ExampleNewFromSpec() {
+	gopt := NewFromSpec([]string{"program", "-v", "-o", "out.txt", "in.txt"}, MultilineSpec{
+		Options: []ShortOption{
+			{Rune: 'v', Disposition: NoArgument},
+			{Rune: 'o', Disposition: RequiredArgument},
+		},
+	})
+	for opt, err := range gopt.All() {
+		if err != nil {
+			_, _ = fmt.Println(err)
+			continue
+		}
+		if opt.Arg != nil {
+			_, _ = fmt.Printf("-%c %s\n", opt.C, *opt.Arg)
+		} else {
+			_, _ = fmt.Printf("-%c\n", opt.C)
+		}
+	}
+	_, _ = fmt.Println(gopt.Remaining())
+	// Output:
+	// -v
+	// -o out.txt
+	// [in.txt]
+}
+
+var _ = Describe("CompileSpec", func() {
+	It("defines the same options as the string form", func() {
+		spec, err := CompileSpec("+ab:c::W;#")
+		Expect(err).NotTo(HaveOccurred())
+		gopt := NewWithSpec([]string{"program"}, spec)
+		expected := New([]string{"program"}, "+ab:c::W;#")
+		Expect(gopt.ShortOptions()).To(Equal(expected.ShortOptions()))
+		Expect(gopt.Ordering()).To(Equal(RequireOrder))
+		Expect(gopt.WEnabled()).To(BeTrue())
+	})
+
+	It("rejects a malformed specification", func() {
+		spec, err := CompileSpec("ab:::")
+		Expect(spec).To(BeNil())
+		Expect(err).To(MatchError(SpecSyntaxError{Pos: 4, Message: "too many ':' after option letter"}))
+	})
+
+	It("is unaffected by changes to the parsers that use it", func() {
+		spec, err := CompileSpec("a")
+		Expect(err).NotTo(HaveOccurred())
+		first := NewWithSpec([]string{"program"}, spec)
+		first.EnableWExtension(true)
+		Expect(first.ShortOptions()).To(HaveKey('W'))
+		Expect(first.SetOrdering(ReturnInOrder)).To(Succeed())
+
+		second := NewWithSpec([]string{"program", "x", "-W"}, spec)
+		Expect(second.ShortOptions()).NotTo(HaveKey('W'))
+		Expect(second.Ordering()).To(Equal(Permute))
+		_, err = second.Getopt()
+		Expect(err).To(MatchError(ErrUnrecognized))
+	})
+
+	It("can be shared among goroutines", func() {
+		spec, err := CompileSpec("ab:")
+		Expect(err).NotTo(HaveOccurred())
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				arg := fmt.Sprint(i)
+				gopt := NewWithSpec([]string{"program", "-a", "-b", arg}, spec)
+				Expect(gopt.Getopt()).To(HaveField("C", 'a'))
+				Expect(gopt.Getopt()).To(HaveField("Arg", HaveValue(Equal(arg))))
+				Expect(gopt.Getopt()).To(BeNil())
+			}()
+		}
+		wg.Wait()
+	})
+})
+
+func ExampleNewWithSpec() {
+	spec, err := CompileSpec("vo:")
+	if err != nil {
+		panic(err)
+	}
+	for _, args := range [][]string{{"program", "-v"}, {"program", "-o", "out.txt"}} {
+		gopt := NewWithSpec(args, spec)
+		for opt, err := range gopt.All() {
+			if err != nil {
+				panic(err)
+			}
+			if opt.Arg != nil {
+				_, _ = fmt.Printf("-%c %s\n", opt.C, *opt.Arg)
+			} else {
+				_, _ = fmt.Printf("-%c\n", opt.C)
+			}
+		}
+	}
+	// Output:
+	// -v
+	// -o out.txt
+}
@@ -0,0 +1,25 @@
+package getopt
+
+// Split parses the options at the start of args, up to the first non-option argument, for programs whose commands have
+// subcommands with options of their own. It returns the options parsed, the first non-option argument as command, and
+// the arguments after the command, untouched, as rest. A second parser can then parse rest with the subcommand's option
+// specification.
+//
+// Arguments are never permuted, regardless of any prefix on opts. If there's no non-option argument, command is empty.
+// A '--' terminator ends the leading options; the argument after it, if any, becomes the command even if it looks like
+// an option. Parsing stops at the first error, which is returned along with the options parsed up to that point.
+func Split(args []string, opts string) (parsed []Opt, command string, rest []string, err error) {
+	g := New(args, opts)
+	g.StopAtFirstNonOption(true)
+	for opt, parseErr := range g.All() {
+		if parseErr != nil {
+			return parsed, "", nil, parseErr
+		}
+		parsed = append(parsed, *opt)
+	}
+	remaining := g.Remaining()
+	if len(remaining) == 0 {
+		return parsed, "", remaining, nil
+	}
+	return parsed, remaining[0], remaining[1:], nil
+}
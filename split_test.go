@@ -0,0 +1,75 @@
+package getopt_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+
+	"github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("Split", func() {
+	It("separates the command from its arguments", func() {
+		parsed, command, rest, err := getopt.Split([]string{"git", "-C", "dir", "remote", "-v", "add", "x"}, "C:v")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(HaveExactElements(MatchFields(IgnoreExtras, Fields{
+			"C":   Equal('C'),
+			"Arg": HaveValue(Equal("dir")),
+		})))
+		Expect(command).To(Equal("remote"))
+		Expect(rest).To(HaveExactElements("-v", "add", "x"))
+	})
+
+	It("handles a missing command", func() {
+		parsed, command, rest, err := getopt.Split([]string{"git", "-v"}, "v")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(HaveLen(1))
+		Expect(command).To(BeEmpty())
+		Expect(rest).To(BeEmpty())
+	})
+
+	It("takes the command after a terminator", func() {
+		_, command, rest, err := getopt.Split([]string{"git", "--", "-v", "x"}, "v")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(command).To(Equal("-v"))
+		Expect(rest).To(HaveExactElements("x"))
+	})
+
+	It("ignores the ordering prefix", func() {
+		_, command, rest, err := getopt.Split([]string{"git", "x", "-v"}, "-v")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(command).To(Equal("x"))
+		Expect(rest).To(HaveExactElements("-v"))
+	})
+
+	It("stops at an error", func() {
+		parsed, _, _, err := getopt.Split([]string{"git", "-v", "-x", "cmd"}, "v")
+		Expect(err).To(MatchError("unrecognized option '-x'"))
+		Expect(parsed).To(HaveLen(1))
+	})
+})
+
+func ExampleSplit() {
+	args := []string{"prg", "-v", "remote", "-v", "add", "origin"}
+	_, command, rest, err := getopt.Split(args, "v")
+	if err != nil {
+		_, _ = fmt.Println(err.Error())
+		return
+	}
+	_, _ = fmt.Printf("command: %s\n", command)
+
+	for opt, err := range getopt.Iterate(append([]string{command}, rest...), "v", &rest) {
+		if err != nil {
+			_, _ = fmt.Println(err.Error())
+			continue
+		}
+		_, _ = fmt.Printf("%s option: %c\n", command, opt.C)
+	}
+	_, _ = fmt.Printf("arguments: %v\n", rest)
+	// Output:
+	// command: remote
+	// remote option: v
+	// arguments: [add origin]
+}
@@ -0,0 +1,232 @@
+package getopt
+
+import (
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+const (
+	usageIndent = "  "
+	usageGap    = "  "
+)
+
+// DefaultUsageGroup is the heading under which [FormatUsage] lists options that have no [Option.Group] when other
+// options do.
+const DefaultUsageGroup = "Options"
+
+// UsageRow describes one option for help output. It is the element type of the slice passed to the template given to
+// [FormatUsageTemplate].
+//
+// Short is the short option, such as "-b", or empty if the option has no short form. Long is the long option, such as
+// "--bravo", or empty if the option has no long form; a negatable option is shown as "--[no-]bravo". HasArg tells
+// whether the option takes an argument, and Description and Group are the option's [Option.Description] and
+// [Option.Group]. Short options without a long form have no group.
+type UsageRow struct {
+	Short       string
+	Long        string
+	HasArg      ArgumentDisposition
+	Description string
+	Group       string
+}
+
+// Placeholder returns the text that follows the option in help output to show what kind of argument it takes: " ARG"
+// for a required argument, " [ARG]" for an optional one, and nothing otherwise.
+func (r UsageRow) Placeholder() string {
+	return argPlaceholder(r.HasArg)
+}
+
+// DefaultUsageTemplate is a template for [FormatUsageTemplate] that lists each option on its own line with its
+// description after a tab. Use it with [text/tabwriter] to align the descriptions.
+var DefaultUsageTemplate = template.Must(template.New("usage").Parse(
+	`{{range .}}  {{if .Short}}{{.Short}}{{if .Long}}, {{end}}{{else}}    {{end}}{{.Long}}{{.Placeholder}}` +
+		"{{if .Description}}\t{{.Description}}{{end}}\n{{end}}"))
+
+// argPlaceholder returns the text that follows an option name in usage output to show what kind of argument the option
+// takes.
+func argPlaceholder(hasArg ArgumentDisposition) string {
+	switch hasArg {
+	case RequiredArgument:
+		return " ARG"
+	case OptionalArgument:
+		return " [ARG]"
+	default:
+		return ""
+	}
+}
+
+// shortOptionLetters returns the option letters defined by the short option specification, in the order they appear
+// in the specification.
+func shortOptionLetters(opts string, info optinfo) []rune {
+	var result []rune
+	seen := map[rune]bool{}
+	for _, c := range opts {
+		if info.HasOpt(c) && !seen[c] {
+			seen[c] = true
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// shortDisposition returns the argument disposition of short option c, accounting for the -W extension, which always
+// requires an argument.
+func shortDisposition(info optinfo, c rune) ArgumentDisposition {
+	if c == 'W' && info.W {
+		return RequiredArgument
+	}
+	return info.Opts[c]
+}
+
+// usageRows lists the options defined by the given short option specification and long options, in the order
+// documented for [FormatUsage].
+func usageRows(opts string, longOptions []Option) []UsageRow {
+	info, _ := parseShortOptionSpec(opts)
+
+	paired := map[rune]bool{}
+	rows := make([]UsageRow, 0, len(longOptions)+len(info.Opts))
+	for _, o := range longOptions {
+		var short string
+		if o.Flag == nil && info.HasOpt(o.Val) && !paired[o.Val] {
+			paired[o.Val] = true
+			short = dash + string(o.Val)
+		}
+		name := o.Name
+		if o.Negatable {
+			name = "[" + negationPrefix + "]" + name
+		}
+		rows = append(rows, UsageRow{
+			Short:       short,
+			Long:        argumentTerminator + name,
+			HasArg:      o.HasArg,
+			Description: o.Description,
+			Group:       o.Group,
+		})
+	}
+	for _, c := range shortOptionLetters(opts, info) {
+		if paired[c] {
+			continue
+		}
+		rows = append(rows, UsageRow{
+			Short:  dash + string(c),
+			HasArg: shortDisposition(info, c),
+		})
+	}
+	return rows
+}
+
+// FormatUsage returns a help listing for the given short option specification and long options, suitable for printing
+// in response to --help. Each option appears on its own line, followed by its Description. Descriptions are aligned
+// in a single column.
+//
+// A long option whose Flag is nil and whose Val is also a short option letter is listed together with that short
+// option, as in "-b, --bravo ARG". Long options are listed first, in the order given, followed by any remaining short
+// options in the order they appear in opts. Negatable options are shown as "--[no-]name". Options that require an
+// argument show "ARG" after the name, and options with an optional argument show "[ARG]".
+//
+// If any option has a [Option.Group], the options are divided into sections, each introduced by a line holding the
+// group name and a colon, with a blank line between sections. Sections appear in the order their groups are first
+// seen in the listing above, and each lists its options in that same order. Options without a group, including short
+// options without a long form, are listed under [DefaultUsageGroup]. The descriptions of all sections are aligned in a
+// single column.
+func FormatUsage(opts string, longOptions []Option) string {
+	rows := usageRows(opts, longOptions)
+
+	lefts := make([]string, len(rows))
+	width := 0
+	for i, row := range rows {
+		switch {
+		case row.Long == "":
+			lefts[i] = row.Short
+		case row.Short == "":
+			lefts[i] = "    " + row.Long
+		default:
+			lefts[i] = row.Short + ", " + row.Long
+		}
+		lefts[i] += row.Placeholder()
+		width = max(width, len([]rune(lefts[i])))
+	}
+
+	var b strings.Builder
+	writeRow := func(i int) {
+		line := usageIndent + lefts[i]
+		if rows[i].Description != "" {
+			line += strings.Repeat(" ", width-len([]rune(lefts[i]))) + usageGap + rows[i].Description
+		}
+		_, _ = b.WriteString(line + "\n")
+	}
+	groups := usageGroups(rows)
+	if groups == nil {
+		for i := range rows {
+			writeRow(i)
+		}
+		return b.String()
+	}
+	for n, group := range groups {
+		if n > 0 {
+			_, _ = b.WriteString("\n")
+		}
+		heading := group
+		if heading == "" {
+			heading = DefaultUsageGroup
+		}
+		_, _ = b.WriteString(heading + ":\n")
+		for i, row := range rows {
+			if row.Group == group {
+				writeRow(i)
+			}
+		}
+	}
+	return b.String()
+}
+
+// usageGroups returns the distinct groups of rows in the order they first appear, or nil if no row has a group.
+func usageGroups(rows []UsageRow) []string {
+	var groups []string
+	grouped := false
+	for _, row := range rows {
+		grouped = grouped || row.Group != ""
+		if !slices.Contains(groups, row.Group) {
+			groups = append(groups, row.Group)
+		}
+	}
+	if !grouped {
+		return nil
+	}
+	return groups
+}
+
+// FormatUsageTemplate returns a help listing produced by executing tmpl with a []UsageRow describing the given options,
+// for programs that want to control the layout themselves. The rows are in the same order as with [FormatUsage].
+// [DefaultUsageTemplate] is a starting point. Any error from executing the template is returned.
+func FormatUsageTemplate(tmpl *template.Template, opts string, longOptions []Option) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, usageRows(opts, longOptions)); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// MinAbbreviations returns, for the name of each option in longOptions, the shortest abbreviation that selects that
+// option when given after "--", such as "verb" for "verbose" alongside "version". The matching rules are those of
+// [Getopt.GetoptLong]. In particular, an option whose name is a prefix of another name makes that prefix unavailable to
+// the other, and an abbreviation shared with options that behave identically selects the first of them. Aliases and
+// the "no-" forms of negatable options are not included. An option that can't be selected by its name at all, because
+// an earlier option has the same name, is omitted.
+func MinAbbreviations(longOptions []Option) map[string]string {
+	g := Getopt{longOptions: longOptions}
+	names := longNames(longOptions)
+	result := make(map[string]string, len(longOptions))
+	for i, o := range longOptions {
+		for start, r := range o.Name {
+			prefix := o.Name[:start+utf8.RuneLen(r)]
+			found, candidates := g.matchLongName(names, prefix, false)
+			if found != -1 && candidates == nil && names[found].index == i && !names[found].negated {
+				result[o.Name] = prefix
+				break
+			}
+		}
+	}
+	return result
+}
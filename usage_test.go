@@ -0,0 +1,179 @@
+package getopt_test
+
+import (
+	"fmt"
+	"text/template"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("FormatUsage", func() {
+	It("aligns descriptions", func() {
+		usage := getopt.FormatUsage("ab:c::", []getopt.Option{
+			{Name: "alpha", Val: 'a', Description: "first"},
+			{Name: "a-much-longer-name", HasArg: getopt.RequiredArgument, Val: 'z', Description: "second"},
+			{Name: "charlie", HasArg: getopt.OptionalArgument, Val: 'c', Description: "third"},
+		})
+		Expect(usage).To(Equal("" +
+			"  -a, --alpha                   first\n" +
+			"      --a-much-longer-name ARG  second\n" +
+			"  -c, --charlie [ARG]           third\n" +
+			"  -b ARG\n"))
+	})
+
+	It("does not pair options with a flag pointer", func() {
+		var flag rune
+		usage := getopt.FormatUsage("a", []getopt.Option{
+			{Name: "alpha", Flag: &flag, Val: 'a', Description: "sets flag"},
+		})
+		Expect(usage).To(Equal("" +
+			"      --alpha  sets flag\n" +
+			"  -a\n"))
+	})
+
+	It("shows negatable options", func() {
+		Expect(getopt.FormatUsage("", []getopt.Option{
+			{Name: "color", Negatable: true, Description: "colorize"},
+		})).To(Equal("      --[no-]color  colorize\n"))
+	})
+
+	It("lists options under their group headings", func() {
+		usage := getopt.FormatUsage("vo:qdh", []getopt.Option{
+			{
+				Name: "output", HasArg: getopt.RequiredArgument, Val: 'o',
+				Description: "write to a file", Group: "Output",
+			},
+			{Name: "debug", Val: 'd', Description: "trace parsing", Group: "Debugging"},
+			{Name: "verbose", Val: 'v', Description: "print more output", Group: "Output"},
+			{Name: "color", Negatable: true, Description: "colorize output"},
+			{Name: "dump-state", Description: "dump internal state", Group: "Debugging"},
+			{Name: "quiet", Val: 'q', Description: "print less", Group: "Output"},
+		})
+		Expect(usage).To(Equal("" +
+			"Output:\n" +
+			"  -o, --output ARG  write to a file\n" +
+			"  -v, --verbose     print more output\n" +
+			"  -q, --quiet       print less\n" +
+			"\n" +
+			"Debugging:\n" +
+			"  -d, --debug       trace parsing\n" +
+			"      --dump-state  dump internal state\n" +
+			"\n" +
+			getopt.DefaultUsageGroup + ":\n" +
+			"      --[no-]color  colorize output\n" +
+			"  -h\n"))
+	})
+
+	It("lists short options in spec order", func() {
+		Expect(getopt.FormatUsage("+zW;y:a::", nil)).To(Equal("" +
+			"  -z\n" +
+			"  -W ARG\n" +
+			"  -y ARG\n" +
+			"  -a [ARG]\n"))
+	})
+})
+
+func ExampleFormatUsage() {
+	longOpts := []getopt.Option{
+		{Name: "verbose", Val: 'v', Description: "print more output"},
+		{Name: "output", HasArg: getopt.RequiredArgument, Val: 'o', Description: "write to a file"},
+		{Name: "color", HasArg: getopt.OptionalArgument, Val: 'C', Description: "colorize output"},
+	}
+	_, _ = fmt.Print(getopt.FormatUsage("vo:h", longOpts))
+	// Output:
+	//   -v, --verbose      print more output
+	//   -o, --output ARG   write to a file
+	//       --color [ARG]  colorize output
+	//   -h
+}
+
+var _ = Describe("MinAbbreviations", func() {
+	It("finds the shortest unique prefixes", func() {
+		Expect(getopt.MinAbbreviations([]getopt.Option{
+			{Name: "verbose", Val: 'v'},
+			{Name: "version", Val: 'V'},
+			{Name: "quiet", Val: 'q'},
+			{Name: "ver", Val: 'r'},
+			{Name: "color", Val: 'c', Negatable: true},
+			{Name: "nothing", Val: 'n'},
+		})).To(Equal(map[string]string{
+			"verbose": "verb",
+			"version": "vers",
+			"quiet":   "q",
+			"ver":     "ver",
+			"color":   "c",
+			"nothing": "not",
+		}))
+	})
+
+	It("agrees with the parser", func() {
+		longopts := []getopt.Option{
+			{Name: "alpha", Val: 'a'},
+			{Name: "alphabet", Val: 'b'},
+			{Name: "beta", Val: 'c', Aliases: []string{"b"}},
+			{Name: "gamma", Val: 'd'},
+			{Name: "gamut", Val: 'd'},
+			{Name: "ünïcode", Val: 'u'},
+			{Name: "ünder", Val: 'v'},
+		}
+		abbrevs := getopt.MinAbbreviations(longopts)
+		Expect(abbrevs).To(HaveLen(len(longopts)))
+		for i, o := range longopts {
+			gopt := getopt.NewLong([]string{"program", "--" + abbrevs[o.Name]}, "", longopts)
+			Expect(gopt.Getopt()).To(HaveField("LongInd", i), o.Name)
+		}
+		Expect(abbrevs).To(HaveKeyWithValue("alpha", "alpha"))
+		Expect(abbrevs).To(HaveKeyWithValue("beta", "b"))
+		Expect(abbrevs).To(HaveKeyWithValue("gamma", "g"))
+		Expect(abbrevs).To(HaveKeyWithValue("gamut", "gamu"))
+		Expect(abbrevs).To(HaveKeyWithValue("ünïcode", "ünï"))
+	})
+
+	It("omits unreachable options", func() {
+		abbrevs := getopt.MinAbbreviations([]getopt.Option{{Name: "x", Val: 'a'}, {Name: "x", Val: 'b'}})
+		Expect(abbrevs).To(Equal(map[string]string{"x": "x"}))
+	})
+})
+
+var _ = Describe("FormatUsageTemplate", func() {
+	longOpts := []getopt.Option{
+		{Name: "verbose", Val: 'v', Description: "print more output"},
+		{Name: "output", HasArg: getopt.RequiredArgument, Val: 'o'},
+		{Name: "color", Negatable: true, Description: "colorize output"},
+	}
+
+	It("renders the default template", func() {
+		Expect(getopt.FormatUsageTemplate(getopt.DefaultUsageTemplate, "vo:h", longOpts)).To(Equal("" +
+			"  -v, --verbose\tprint more output\n" +
+			"  -o, --output ARG\n" +
+			"      --[no-]color\tcolorize output\n" +
+			"  -h\n"))
+	})
+
+	It("renders a custom template", func() {
+		tmpl := template.Must(template.New("custom").Parse(
+			`{{range .}}{{.Short}}|{{.Long}}|{{if eq .HasArg 1}}required{{end}}|{{.Description}};{{end}}`))
+		Expect(getopt.FormatUsageTemplate(tmpl, "vo:h", longOpts)).To(Equal("" +
+			"-v|--verbose||print more output;" +
+			"-o|--output|required|;" +
+			"|--[no-]color||colorize output;" +
+			"-h|||;"))
+	})
+
+	It("provides the group to the template", func() {
+		tmpl := template.Must(template.New("groups").Parse(`{{range .}}{{.Long}}={{.Group}};{{end}}`))
+		Expect(getopt.FormatUsageTemplate(tmpl, "", []getopt.Option{
+			{Name: "alpha", Group: "First"},
+			{Name: "bravo"},
+		})).To(Equal("--alpha=First;--bravo=;"))
+	})
+
+	It("reports template errors", func() {
+		tmpl := template.Must(template.New("bad").Parse(`{{range .}}{{.Missing}}{{end}}`))
+		_, err := getopt.FormatUsageTemplate(tmpl, "v", nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,28 @@
+package getopt
+
+// ValidateOptions checks a list of long options for definitions that can't work as intended. It returns an
+// [EmptyOptionNameError] for an option with no name, and a [DuplicateOptionError] when two options can be given by the
+// same name, including the "no-" form of a [Option.Negatable] option. Only the first problem is reported.
+//
+// Options may share a Flag pointer. When they have different Vals, they are distinct options that set the same
+// variable to different values, as with "--verbose" and "--brief" in the GNU documentation; an abbreviation matching
+// both is reported as ambiguous. When they have the same Val and argument disposition, they are synonyms, and an
+// abbreviation matching both selects the first. Neither case is an error.
+func ValidateOptions(longOptions []Option) error {
+	for i, o := range longOptions {
+		if o.Name == "" {
+			return EmptyOptionNameError{Index: i}
+		}
+	}
+	seen := map[string]bool{}
+	for _, n := range longNames(longOptions) {
+		if seen[n.name] {
+			return DuplicateOptionError{
+				Name:   n.name,
+				Prefix: argumentTerminator,
+			}
+		}
+		seen[n.name] = true
+	}
+	return nil
+}
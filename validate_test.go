@@ -0,0 +1,53 @@
+package getopt_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/rkennedy/go-getopt"
+)
+
+var _ = Describe("ValidateOptions", func() {
+	It("accepts distinct options", func() {
+		var flag rune
+		Expect(ValidateOptions([]Option{
+			{Name: "alpha", Val: 'a'},
+			{Name: "verbose", Flag: &flag, Val: 1},
+			{Name: "brief", Flag: &flag, Val: 0},
+			{Name: "color", Negatable: true},
+		})).To(Succeed())
+	})
+
+	It("accepts an empty list", func() {
+		Expect(ValidateOptions(nil)).To(Succeed())
+	})
+
+	It("detects duplicate names", func() {
+		Expect(ValidateOptions([]Option{
+			{Name: "alpha", Val: 'a'},
+			{Name: "bravo", Val: 'b'},
+			{Name: "alpha", HasArg: RequiredArgument, Val: 'A'},
+		})).To(MatchError(DuplicateOptionError{Name: "alpha", Prefix: "--"}))
+	})
+
+	It("detects names that collide with negations", func() {
+		Expect(ValidateOptions([]Option{
+			{Name: "color", Negatable: true},
+			{Name: "no-color"},
+		})).To(MatchError("option '--no-color' is defined more than once"))
+	})
+
+	It("detects empty names", func() {
+		Expect(ValidateOptions([]Option{
+			{Name: "alpha", Val: 'a'},
+			{Name: "", Val: 'b'},
+		})).To(MatchError(EmptyOptionNameError{Index: 1}))
+	})
+
+	It("reports empty names before duplicates", func() {
+		Expect(ValidateOptions([]Option{
+			{Name: "alpha"},
+			{Name: "alpha"},
+			{Name: ""},
+		})).To(MatchError("long option at index 2 has an empty name"))
+	})
+})